@@ -0,0 +1,205 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Game member roles, each role including the permissions of the previous
+// ones:
+//   - viewers can read the game and its advertisement configs
+//   - editors can change the advertisement configs and their placements,
+//     the experiments, the configurations and the placements catalog of the
+//     game and submit the advertisement configs for review
+//   - publishers can approve, publish, archive and roll back the configs
+//   - owners can change and delete the game, delete its configs and manage
+//     its members
+const (
+	roleViewer    = "viewer"
+	roleEditor    = "editor"
+	rolePublisher = "publisher"
+	roleOwner     = "owner"
+)
+
+// gameRoles lists the member roles from the least to the most privileged.
+var gameRoles = []string{roleViewer, roleEditor, rolePublisher, roleOwner}
+
+// publisherStatuses lists the advertisement config statuses only the
+// publishers can move a config to.
+var publisherStatuses = []string{statusApproved, statusPublished, statusArchived}
+
+// editorCollections lists the game scoped collections, besides the
+// advertisement configs and their placements, the editors of the record game
+// can change.
+var editorCollections = []string{
+	experimentsCollectionName,
+	configurationTemplatesCollectionName,
+	configurationsCollectionName,
+}
+
+// hasGameRole reports whether the user has at least the given role in the game.
+func hasGameRole(app core.App, userID string, gameID string, role string) (bool, error) {
+	member, err := app.FindFirstRecordByFilter(
+		gameMembersCollectionName,
+		"user = {:user} && game_id = {:game}",
+		dbx.Params{"user": userID, "game": gameID},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return slices.Index(gameRoles, member.GetString("role")) >= slices.Index(gameRoles, role), nil
+}
+
+// requireGameRole makes sure the request auth record has at least the given
+// role in every game. Superusers are allowed everything.
+func requireGameRole(e *core.RequestEvent, role string, gameIDs ...string) error {
+	if e.HasSuperuserAuth() {
+		return nil
+	}
+
+	if e.Auth == nil {
+		return e.UnauthorizedError("the request requires valid authorization token", nil)
+	}
+
+	for _, gameID := range gameIDs {
+		allowed, err := hasGameRole(e.App, e.Auth.Id, gameID, role)
+		if err != nil {
+			return e.InternalServerError("failed to check the game membership", err)
+		}
+		if !allowed {
+			return e.ForbiddenError(fmt.Sprintf("the %s role is required in the game %s", role, gameID), nil)
+		}
+	}
+
+	return nil
+}
+
+// recordGameIDs returns the ids of the games a game scoped record belongs to.
+func recordGameIDs(app core.App, record *core.Record) ([]string, error) {
+	switch record.Collection().Name {
+	case gamesCollectionName:
+		return []string{record.Id}, nil
+	case advertisementsPlacementsCollectionName:
+		config, err := app.FindRecordById(advertisementConfigsCollectionName, record.GetString("advertisement_id"))
+		if err != nil {
+			// let the record validation report the invalid relation
+			return nil, nil
+		}
+		return configGameIDs(config), nil
+	default:
+		return configGameIDs(record), nil
+	}
+}
+
+// requireRecordGameRole makes sure the request auth record has at least the
+// given role in the games of the record and, on update, in its original games.
+func requireRecordGameRole(e *core.RecordRequestEvent, role string) error {
+	gameIDs, err := recordGameIDs(e.App, e.Record)
+	if err != nil {
+		return e.InternalServerError("failed to find the record games", err)
+	}
+
+	if original := e.Record.Original(); !e.Record.IsNew() && original != nil {
+		originalGameIDs, err := recordGameIDs(e.App, original)
+		if err != nil {
+			return e.InternalServerError("failed to find the record games", err)
+		}
+		for _, gameID := range originalGameIDs {
+			if !slices.Contains(gameIDs, gameID) {
+				gameIDs = append(gameIDs, gameID)
+			}
+		}
+	}
+
+	return requireGameRole(e.RequestEvent, role, gameIDs...)
+}
+
+// requireOwner restricts the game changes, the game membership management
+// and the advertisement config deletions to the owners of the games.
+func requireOwner(e *core.RecordRequestEvent) error {
+	if err := requireRecordGameRole(e, roleOwner); err != nil {
+		return err
+	}
+
+	return e.Next()
+}
+
+// requireEditor restricts the advertisement config creation, the placement
+// changes and the changes of the editorCollections records to the editors of
+// all of the record games.
+func requireEditor(e *core.RecordRequestEvent) error {
+	if err := requireRecordGameRole(e, roleEditor); err != nil {
+		return err
+	}
+
+	return e.Next()
+}
+
+// requirePlacementCatalogEditor restricts the placements catalog changes to
+// the editors of the placement game, the global placements shared by all of
+// the games being reserved to the superusers.
+func requirePlacementCatalogEditor(e *core.RecordRequestEvent) error {
+	isGlobal := e.Record.GetString("game_id") == ""
+	if original := e.Record.Original(); !e.Record.IsNew() && original != nil {
+		isGlobal = isGlobal || original.GetString("game_id") == ""
+	}
+	if isGlobal && !e.HasSuperuserAuth() {
+		return e.ForbiddenError("only superusers can change the global placements", nil)
+	}
+
+	return requireEditor(e)
+}
+
+// requireAdConfigUpdateRole restricts the advertisement config changes to the
+// editors of its games, the approval, publishing and archiving being reserved
+// to the publishers.
+func requireAdConfigUpdateRole(e *core.RecordRequestEvent) error {
+	role := roleEditor
+
+	status := e.Record.GetString("status")
+	if status != e.Record.Original().GetString("status") && slices.Contains(publisherStatuses, status) {
+		role = rolePublisher
+	}
+
+	if err := requireRecordGameRole(e, role); err != nil {
+		return err
+	}
+
+	return e.Next()
+}
+
+// addGameOwner makes the user creating a game its owner.
+func addGameOwner(e *core.RecordRequestEvent) error {
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		e.App = txApp
+
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		if e.Auth == nil || e.Auth.Collection().Name != usersCollectionName {
+			return nil
+		}
+
+		collection, err := txApp.FindCollectionByNameOrId(gameMembersCollectionName)
+		if err != nil {
+			return err
+		}
+
+		member := core.NewRecord(collection)
+		member.Set("user", e.Auth.Id)
+		member.Set("game_id", e.Record.Id)
+		member.Set("role", roleOwner)
+
+		return txApp.Save(member)
+	})
+}
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedGameMember makes the seeded regular user a member of the test game
+// with the given role.
+func seedGameMember(t testing.TB, app core.App, role string) {
+	user, err := app.FindAuthRecordByEmail(usersCollectionName, userEmail)
+	require.NoError(t, err)
+
+	createTestRecord(t, app, gameMembersCollectionName, map[string]any{
+		"user":    user.Id,
+		"game_id": testGameRecordID,
+		"role":    role,
+	})
+}
+
+const (
+	testExperimentRecordID    = "testexperiment1"
+	testConfigurationRecordID = "testsettings001"
+	testGamePlacementID       = "testcatalog0002"
+)
+
+// accessRoles lists the roles of the access matrix, the empty role standing
+// for a user who isn't a member of the game.
+var accessRoles = []string{"", roleViewer, roleEditor, rolePublisher, roleOwner}
+
+func TestGameRoleAccess(t *testing.T) {
+	seedAccess := func(t testing.TB, app core.App) {
+		seedClientConfig(t, app)
+		createTestRecord(t, app, placementsCollectionName, map[string]any{
+			"id":         testCatalogPlacementID,
+			"name":       "Screen/Shop/Open",
+			"ad_formats": []int{2},
+		})
+	}
+	seedGameScoped := func(t testing.TB, app core.App) {
+		seedAccess(t, app)
+		createTestRecord(t, app, placementsCollectionName, map[string]any{
+			"id":         testGamePlacementID,
+			"name":       "Screen/Shop/Close",
+			"ad_formats": []int{2},
+			"game_id":    testGameRecordID,
+		})
+		createTestRecord(t, app, experimentsCollectionName, map[string]any{
+			"id":      testExperimentRecordID,
+			"key":     "control",
+			"game_id": testGameRecordID,
+		})
+		createTestRecord(t, app, configurationTemplatesCollectionName, map[string]any{
+			"id":      testTemplateRecordID,
+			"name":    "AD_SETTINGS",
+			"game_id": testGameRecordID,
+		})
+		createTestRecord(t, app, configurationsCollectionName, map[string]any{
+			"id":          testConfigurationRecordID,
+			"name":        "first",
+			"game_id":     testGameRecordID,
+			"template_id": testTemplateRecordID,
+			"data":        map[string]any{"banner": false},
+			"is_latest":   true,
+		})
+	}
+	seedInReview := func(t testing.TB, app core.App) {
+		seedConfigWithStatus(t, app, statusInReview)
+	}
+
+	operations := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		seed   func(t testing.TB, app core.App)
+		// expected status of each role of accessRoles
+		expected []int
+	}{
+		{
+			name:     "view game",
+			method:   http.MethodGet,
+			url:      "/api/collections/games/records/" + testGameRecordID,
+			seed:     seedAccess,
+			expected: []int{404, 200, 200, 200, 200},
+		},
+		{
+			name:     "update game",
+			method:   http.MethodPatch,
+			url:      "/api/collections/games/records/" + testGameRecordID,
+			body:     `{"name":"Renamed"}`,
+			seed:     seedAccess,
+			expected: []int{404, 403, 403, 403, 200},
+		},
+		{
+			name:     "delete game",
+			method:   http.MethodDelete,
+			url:      "/api/collections/games/records/" + testGameRecordID,
+			seed:     seedAccess,
+			expected: []int{404, 403, 403, 403, 204},
+		},
+		{
+			name:     "view advertisement config",
+			method:   http.MethodGet,
+			url:      "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			seed:     seedAccess,
+			expected: []int{404, 200, 200, 200, 200},
+		},
+		{
+			name:     "create advertisement config",
+			method:   http.MethodPost,
+			url:      "/api/collections/advertisement_configs/records",
+			body:     `{"name":"new","experiment_id":"control","game_id":["` + testGameRecordID + `"]}`,
+			seed:     seedAccess,
+			expected: []int{403, 403, 200, 200, 200},
+		},
+		{
+			name:     "update advertisement config",
+			method:   http.MethodPatch,
+			url:      "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			body:     `{"banner_refresh_rate":90}`,
+			seed:     seedAccess,
+			expected: []int{404, 403, 200, 200, 200},
+		},
+		{
+			name:     "approve advertisement config",
+			method:   http.MethodPatch,
+			url:      "/api/collections/advertisement_configs/records/" + testSecondConfigRecordID,
+			body:     `{"status":"approved"}`,
+			seed:     seedInReview,
+			expected: []int{404, 403, 403, 200, 200},
+		},
+		{
+			name:     "rollback advertisement config",
+			method:   http.MethodPost,
+			url:      "/api/configs/" + testSecondConfigRecordID + "/rollback",
+			body:     `{"version":1}`,
+			seed:     seedInReview,
+			expected: []int{403, 403, 403, 200, 200},
+		},
+		{
+			name:     "delete advertisement config",
+			method:   http.MethodDelete,
+			url:      "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			seed:     seedAccess,
+			expected: []int{404, 403, 403, 403, 204},
+		},
+		{
+			name:     "view placement",
+			method:   http.MethodGet,
+			url:      "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			seed:     seedAccess,
+			expected: []int{404, 200, 200, 200, 200},
+		},
+		{
+			name:     "create placement",
+			method:   http.MethodPost,
+			url:      "/api/collections/advertisements_placements/records",
+			body:     `{"advertisement_id":"` + testConfigRecordID + `","placement_id":"` + testCatalogPlacementID + `","ad_format":2}`,
+			seed:     seedAccess,
+			expected: []int{403, 403, 200, 200, 200},
+		},
+		{
+			name:     "update placement",
+			method:   http.MethodPatch,
+			url:      "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			body:     `{"min_level":5}`,
+			seed:     seedAccess,
+			expected: []int{404, 403, 200, 200, 200},
+		},
+		{
+			name:     "delete placement",
+			method:   http.MethodDelete,
+			url:      "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			seed:     seedAccess,
+			expected: []int{404, 403, 204, 204, 204},
+		},
+		{
+			name:     "view global catalog placement",
+			method:   http.MethodGet,
+			url:      "/api/collections/placements/records/" + testCatalogPlacementID,
+			seed:     seedGameScoped,
+			expected: []int{200, 200, 200, 200, 200},
+		},
+		{
+			name:     "create global catalog placement",
+			method:   http.MethodPost,
+			url:      "/api/collections/placements/records",
+			body:     `{"name":"Screen/Shop/Buy","ad_formats":[2]}`,
+			seed:     seedGameScoped,
+			expected: []int{403, 403, 403, 403, 403},
+		},
+		{
+			name:     "update global catalog placement",
+			method:   http.MethodPatch,
+			url:      "/api/collections/placements/records/" + testCatalogPlacementID,
+			body:     `{"name":"Screen/Shop/Buy"}`,
+			seed:     seedGameScoped,
+			expected: []int{404, 404, 404, 404, 404},
+		},
+		{
+			name:     "view game catalog placement",
+			method:   http.MethodGet,
+			url:      "/api/collections/placements/records/" + testGamePlacementID,
+			seed:     seedGameScoped,
+			expected: []int{404, 200, 200, 200, 200},
+		},
+		{
+			name:     "create game catalog placement",
+			method:   http.MethodPost,
+			url:      "/api/collections/placements/records",
+			body:     `{"name":"Screen/Shop/Buy","ad_formats":[2],"game_id":"` + testGameRecordID + `"}`,
+			seed:     seedGameScoped,
+			expected: []int{403, 403, 200, 200, 200},
+		},
+		{
+			name:     "make game catalog placement global",
+			method:   http.MethodPatch,
+			url:      "/api/collections/placements/records/" + testGamePlacementID,
+			body:     `{"game_id":""}`,
+			seed:     seedGameScoped,
+			expected: []int{404, 403, 403, 403, 403},
+		},
+		{
+			name:     "delete game catalog placement",
+			method:   http.MethodDelete,
+			url:      "/api/collections/placements/records/" + testGamePlacementID,
+			seed:     seedGameScoped,
+			expected: []int{404, 403, 204, 204, 204},
+		},
+		{
+			name:     "view experiment",
+			method:   http.MethodGet,
+			url:      "/api/collections/experiments/records/" + testExperimentRecordID,
+			seed:     seedGameScoped,
+			expected: []int{404, 200, 200, 200, 200},
+		},
+		{
+			name:     "create experiment",
+			method:   http.MethodPost,
+			url:      "/api/collections/experiments/records",
+			body:     `{"key":"aggressive","game_id":"` + testGameRecordID + `"}`,
+			seed:     seedGameScoped,
+			expected: []int{403, 403, 200, 200, 200},
+		},
+		{
+			name:     "start experiment",
+			method:   http.MethodPatch,
+			url:      "/api/collections/experiments/records/" + testExperimentRecordID,
+			body:     `{"running":true}`,
+			seed:     seedGameScoped,
+			expected: []int{404, 403, 200, 200, 200},
+		},
+		{
+			name:     "delete experiment",
+			method:   http.MethodDelete,
+			url:      "/api/collections/experiments/records/" + testExperimentRecordID,
+			seed:     seedGameScoped,
+			expected: []int{404, 403, 204, 204, 204},
+		},
+		{
+			name:     "view configuration template",
+			method:   http.MethodGet,
+			url:      "/api/collections/configuration_templates/records/" + testTemplateRecordID,
+			seed:     seedGameScoped,
+			expected: []int{404, 200, 200, 200, 200},
+		},
+		{
+			name:     "create configuration template",
+			method:   http.MethodPost,
+			url:      "/api/collections/configuration_templates/records",
+			body:     `{"name":"SHOP_SETTINGS","game_id":"` + testGameRecordID + `"}`,
+			seed:     seedGameScoped,
+			expected: []int{403, 403, 200, 200, 200},
+		},
+		{
+			name:     "view configuration",
+			method:   http.MethodGet,
+			url:      "/api/collections/configurations/records/" + testConfigurationRecordID,
+			seed:     seedGameScoped,
+			expected: []int{404, 200, 200, 200, 200},
+		},
+		{
+			name:     "update configuration",
+			method:   http.MethodPatch,
+			url:      "/api/collections/configurations/records/" + testConfigurationRecordID,
+			body:     `{"data":{"banner":true}}`,
+			seed:     seedGameScoped,
+			expected: []int{404, 403, 200, 200, 200},
+		},
+		{
+			name:     "delete configuration",
+			method:   http.MethodDelete,
+			url:      "/api/collections/configurations/records/" + testConfigurationRecordID,
+			seed:     seedGameScoped,
+			expected: []int{404, 403, 204, 204, 204},
+		},
+	}
+
+	for _, operation := range operations {
+		for i, role := range accessRoles {
+			name := role
+			if name == "" {
+				name = "non member"
+			}
+
+			scenario := &tests.ApiScenario{
+				Name:   fmt.Sprintf("%s as %s", operation.name, name),
+				Method: operation.method,
+				URL:    operation.url,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				ExpectedStatus:  operation.expected[i],
+				ExpectedContent: []string{"{"},
+				BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+					operation.seed(t, app)
+					if role != "" {
+						seedGameMember(t, app, role)
+					}
+					authenticateAsUser(t, app, e)
+				},
+				TestAppFactory: setupTestApp,
+			}
+			if operation.body != "" {
+				scenario.Body = strings.NewReader(operation.body)
+			}
+			if operation.expected[i] == 204 {
+				scenario.ExpectedContent = nil
+				scenario.NotExpectedContent = []string{"{"}
+			}
+
+			scenario.Test(t)
+		}
+	}
+}
+
+func TestGameMembers(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "guests don't list the records of the games without members",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/games/records",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "non members don't list the game records",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/advertisement_configs/records",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "members list the game records",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/advertisement_configs/records",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":1`, `"id":"` + testConfigRecordID + `"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, roleViewer)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "the game creator becomes its owner",
+			Method: http.MethodPost,
+			URL:    "/api/collections/games/records",
+			Body:   strings.NewReader(`{"game_id":"studio.sun.new"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"game_id":"studio.sun.new"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				user, err := app.FindAuthRecordByEmail(usersCollectionName, userEmail)
+				require.NoError(t, err)
+
+				game, err := app.FindFirstRecordByData(gamesCollectionName, "game_id", "studio.sun.new")
+				require.NoError(t, err)
+
+				allowed, err := hasGameRole(app, user.Id, game.Id, roleOwner)
+				require.NoError(t, err)
+				assert.True(t, allowed)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "only the owners manage the members",
+			Method: http.MethodPost,
+			URL:    "/api/collections/game_members/records",
+			Body:   strings.NewReader(`{"user":"otheruser000001","game_id":"` + testGameRecordID + `","role":"owner"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The owner role is required in the game " + testGameRecordID + "."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, rolePublisher)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "configs shared with another game require the role in every game",
+			Method:          http.MethodPatch,
+			URL:             "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			Body:            strings.NewReader(`{"game_id":["` + testGameRecordID + `","othergame000001"]}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The editor role is required in the game othergame000001."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				createTestRecord(t, app, gamesCollectionName, map[string]any{
+					"id":      "othergame000001",
+					"game_id": "studio.sun.other",
+				})
+				seedGameMember(t, app, roleOwner)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"config-manager/adtypes"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// validateAdConfigEnums makes sure the numeric advertisement enum fields of
+// an advertisement config hold known values.
+func validateAdConfigEnums(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	if position := adtypes.BannerPosition(record.GetInt("banner_position")); !position.IsValid() {
+		errs["banner_position"] = unknownEnumError(adtypes.BannerPositions())
+	}
+
+	return errs, nil
+}
+
+// validatePlacementEnums makes sure the numeric advertisement enum fields of
+// an advertisement placement hold known values.
+func validatePlacementEnums(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	if format := adtypes.AdFormat(record.GetInt("ad_format")); !format.IsValid() {
+		errs["ad_format"] = unknownEnumError(adtypes.AdFormats())
+	}
+	if action := adtypes.Action(record.GetInt("action")); !action.IsValid() {
+		errs["action"] = unknownEnumError(adtypes.Actions())
+	}
+
+	return errs, nil
+}
+
+// unknownEnumError lists the allowed codes of an advertisement enum.
+func unknownEnumError[T interface {
+	~int
+	fmt.Stringer
+}](allowed []T) validation.Error {
+	message := "must be one of"
+	for i, value := range allowed {
+		if i > 0 {
+			message += ","
+		}
+		message += fmt.Sprintf(" %d (%s)", value, value)
+	}
+
+	return validation.NewError("validation_unknown_enum_value", message)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestAdEnumsValidation(t *testing.T) {
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedClientConfig(t, app)
+		authenticateAsAdmin(t, app, e)
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "unknown banner position",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			Body:   strings.NewReader(`{"banner_position":7}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"banner_position":{"code":"validation_unknown_enum_value"`,
+				`Must be one of 0 (top), 1 (bottom), 2 (top_left), 3 (top_right), 4 (bottom_left), 5 (bottom_right), 6 (center).`,
+			},
+			BeforeTestFunc: seed,
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "unknown ad format and action",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			Body:   strings.NewReader(`{"ad_format":9,"action":-1}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"ad_format":{"code":"validation_unknown_enum_value"`,
+				`"action":{"code":"validation_unknown_enum_value"`,
+			},
+			BeforeTestFunc: seed,
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "unknown ad format allowed by a catalog placement",
+			Method: http.MethodPost,
+			URL:    "/api/collections/placements/records",
+			Body:   strings.NewReader(`{"name":"Screen/Shop/Open","ad_formats":[1,5]}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"ad_formats":{"code":"validation_unknown_enum_value"`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:   "known values",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			Body:   strings.NewReader(`{"ad_format":4,"action":3,"custom_ad_unit_id":"app-open-unit"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"ad_format":4`, `"action":3`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
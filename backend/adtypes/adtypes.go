@@ -0,0 +1,137 @@
+// Package adtypes defines the named values of the numeric advertisement
+// enums stored in the advertisement configs and placements.
+//
+// The numeric codes are stable and shared with the game clients, so new
+// values must only ever be appended.
+package adtypes
+
+import (
+	"fmt"
+	"slices"
+)
+
+// AdFormat is the format of the ad shown by a placement (ad_format).
+type AdFormat int
+
+const (
+	AdFormatBanner AdFormat = iota
+	AdFormatInterstitial
+	AdFormatRewarded
+	AdFormatRewardedInterstitial
+	AdFormatAppOpen
+)
+
+var adFormatNames = []string{"banner", "interstitial", "rewarded", "rewarded_interstitial", "app_open"}
+
+// AdFormats returns all the valid ad formats.
+func AdFormats() []AdFormat {
+	return values[AdFormat](adFormatNames)
+}
+
+// ParseAdFormat returns the ad format with the given name.
+func ParseAdFormat(name string) (AdFormat, error) {
+	return parse[AdFormat](adFormatNames, "ad format", name)
+}
+
+// IsValid reports whether the ad format is a known one.
+func (f AdFormat) IsValid() bool {
+	return f >= 0 && int(f) < len(adFormatNames)
+}
+
+// String returns the ad format name.
+func (f AdFormat) String() string {
+	return name(adFormatNames, int(f))
+}
+
+// Action is what a placement does with its ad (action).
+type Action int
+
+const (
+	ActionShow Action = iota
+	ActionLoad
+	ActionHide
+	ActionDestroy
+)
+
+var actionNames = []string{"show", "load", "hide", "destroy"}
+
+// Actions returns all the valid placement actions.
+func Actions() []Action {
+	return values[Action](actionNames)
+}
+
+// ParseAction returns the placement action with the given name.
+func ParseAction(name string) (Action, error) {
+	return parse[Action](actionNames, "action", name)
+}
+
+// IsValid reports whether the action is a known one.
+func (a Action) IsValid() bool {
+	return a >= 0 && int(a) < len(actionNames)
+}
+
+// String returns the action name.
+func (a Action) String() string {
+	return name(actionNames, int(a))
+}
+
+// BannerPosition is the screen position of the banner (banner_position).
+type BannerPosition int
+
+const (
+	BannerPositionTop BannerPosition = iota
+	BannerPositionBottom
+	BannerPositionTopLeft
+	BannerPositionTopRight
+	BannerPositionBottomLeft
+	BannerPositionBottomRight
+	BannerPositionCenter
+)
+
+var bannerPositionNames = []string{"top", "bottom", "top_left", "top_right", "bottom_left", "bottom_right", "center"}
+
+// BannerPositions returns all the valid banner positions.
+func BannerPositions() []BannerPosition {
+	return values[BannerPosition](bannerPositionNames)
+}
+
+// ParseBannerPosition returns the banner position with the given name.
+func ParseBannerPosition(name string) (BannerPosition, error) {
+	return parse[BannerPosition](bannerPositionNames, "banner position", name)
+}
+
+// IsValid reports whether the banner position is a known one.
+func (p BannerPosition) IsValid() bool {
+	return p >= 0 && int(p) < len(bannerPositionNames)
+}
+
+// String returns the banner position name.
+func (p BannerPosition) String() string {
+	return name(bannerPositionNames, int(p))
+}
+
+func values[T ~int](names []string) []T {
+	result := make([]T, len(names))
+	for i := range names {
+		result[i] = T(i)
+	}
+
+	return result
+}
+
+func parse[T ~int](names []string, kind string, value string) (T, error) {
+	code := slices.Index(names, value)
+	if code < 0 {
+		return 0, fmt.Errorf("unknown %s %q", kind, value)
+	}
+
+	return T(code), nil
+}
+
+func name(names []string, code int) string {
+	if code < 0 || code >= len(names) {
+		return fmt.Sprintf("unknown(%d)", code)
+	}
+
+	return names[code]
+}
@@ -0,0 +1,48 @@
+package adtypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStableCodes(t *testing.T) {
+	// the codes are stored in the database and read by the game clients
+	assert.EqualValues(t, 1, AdFormatInterstitial)
+	assert.EqualValues(t, 4, AdFormatAppOpen)
+	assert.EqualValues(t, 3, ActionDestroy)
+	assert.EqualValues(t, 1, BannerPositionBottom)
+	assert.EqualValues(t, 6, BannerPositionCenter)
+}
+
+func TestNames(t *testing.T) {
+	for _, format := range AdFormats() {
+		parsed, err := ParseAdFormat(format.String())
+		require.NoError(t, err)
+		assert.Equal(t, format, parsed)
+	}
+	for _, action := range Actions() {
+		parsed, err := ParseAction(action.String())
+		require.NoError(t, err)
+		assert.Equal(t, action, parsed)
+	}
+	for _, position := range BannerPositions() {
+		parsed, err := ParseBannerPosition(position.String())
+		require.NoError(t, err)
+		assert.Equal(t, position, parsed)
+	}
+
+	assert.Equal(t, "rewarded_interstitial", AdFormatRewardedInterstitial.String())
+	assert.Equal(t, "unknown(9)", AdFormat(9).String())
+
+	_, err := ParseBannerPosition("middle")
+	assert.EqualError(t, err, `unknown banner position "middle"`)
+}
+
+func TestIsValid(t *testing.T) {
+	assert.True(t, AdFormatBanner.IsValid())
+	assert.False(t, AdFormat(-1).IsValid())
+	assert.False(t, Action(4).IsValid())
+	assert.False(t, BannerPosition(7).IsValid())
+}
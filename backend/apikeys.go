@@ -0,0 +1,286 @@
+package main
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// apiKeyPrefixLength is the number of leading characters of a key kept
+	// in clear to identify it.
+	apiKeyPrefixLength = 8
+
+	// apiKeyLastUsedInterval throttles the updates of the last use time of
+	// the keys.
+	apiKeyLastUsedInterval = time.Minute
+
+	// clientGameContextKey is the request store key of the game the API key
+	// of the request grants access to.
+	clientGameContextKey = "clientGame"
+)
+
+// API key scopes. The client keys are shipped in the game builds, so they
+// only grant access to the resolved client config, while the promotion keys
+// grant access to all of the published configs of the game. The keys saved
+// without a scope are client keys.
+const (
+	apiKeyScopeClient    = "client"
+	apiKeyScopePromotion = "promotion"
+)
+
+// apiKeyScopes lists the valid API key scopes.
+var apiKeyScopes = []string{apiKeyScopeClient, apiKeyScopePromotion}
+
+// generateAPIKey returns a new random plaintext API key.
+func generateAPIKey() string {
+	return "cm_" + security.RandomString(40)
+}
+
+// hashAPIKey returns the stored hash of a plaintext API key. The keys being
+// random, a plain SHA-256 hash is enough to look them up safely.
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// findAPIKey returns the usable (neither revoked nor expired) API key
+// matching the plaintext key.
+func findAPIKey(app core.App, key string, now time.Time) (*core.Record, error) {
+	apiKey, err := app.FindFirstRecordByData(apiKeysCollectionName, "key_hash", hashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := apiKey.GetDateTime("expires_at")
+	if apiKey.GetBool("revoked") || (!expiresAt.IsZero() && !now.Before(expiresAt.Time())) {
+		return nil, sql.ErrNoRows
+	}
+
+	return apiKey, nil
+}
+
+// apiKeyScope returns the scope of an API key.
+func apiKeyScope(apiKey *core.Record) string {
+	return cmp.Or(apiKey.GetString("scope"), apiKeyScopeClient)
+}
+
+// requireGameAPIKey returns the middleware authenticating the requests with
+// an API key of the requested game and of the given scope. The key only
+// grants access to its own game, which is made available to the handler
+// under clientGameContextKey.
+func requireGameAPIKey(scope string) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		key := e.Request.Header.Get(clientKeyHeader)
+		if key == "" {
+			return e.UnauthorizedError("missing or invalid API key", nil)
+		}
+
+		now := time.Now()
+		apiKey, err := findAPIKey(e.App, key, now)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return e.UnauthorizedError("missing or invalid API key", nil)
+			}
+			return e.InternalServerError("failed to check the API key", err)
+		}
+
+		if apiKeyScope(apiKey) != scope {
+			return e.ForbiddenError(fmt.Sprintf("the %s scope is required", scope), nil)
+		}
+
+		game, err := findGameByBundleID(e.App, e.Request.PathValue("game_id"))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return e.NotFoundError("game not found", nil)
+			}
+			return e.InternalServerError("failed to load game", err)
+		}
+
+		if apiKey.GetString("game_id") != game.Id {
+			return e.ForbiddenError("the API key doesn't grant access to this game", nil)
+		}
+
+		if now.Sub(apiKey.GetDateTime("last_used_at").Time()) >= apiKeyLastUsedInterval {
+			// the key usage isn't a change of the key record, so its hooks are skipped
+			_, err := e.App.DB().Update(
+				apiKeysCollectionName,
+				dbx.Params{"last_used_at": types.NowDateTime().String()},
+				dbx.HashExp{"id": apiKey.Id},
+			).Execute()
+			if err != nil {
+				slog.Warn("failed to update the API key last use", "id", apiKey.Id, "error", err)
+			}
+		}
+
+		e.Set(clientGameContextKey, game)
+
+		return e.Next()
+	}
+}
+
+// newAPIKey saves a new API key of the game with the given scope and returns
+// it along with its plaintext key.
+func newAPIKey(app core.App, gameID string, label string, scope string, expiresAt types.DateTime, author *core.Record) (*core.Record, string, error) {
+	collection, err := app.FindCollectionByNameOrId(apiKeysCollectionName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := generateAPIKey()
+
+	apiKey := core.NewRecord(collection)
+	apiKey.Set("game_id", gameID)
+	apiKey.Set("label", label)
+	apiKey.Set("scope", scope)
+	apiKey.Set("key_hash", hashAPIKey(key))
+	apiKey.Set("key_prefix", key[:apiKeyPrefixLength])
+	apiKey.Set("expires_at", expiresAt)
+	if author != nil {
+		apiKey.Set("created_by", author.Id)
+	}
+
+	if err := app.Save(apiKey); err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+// apiKeyResponse serves an API key record, along with its plaintext key when
+// it was just created.
+func apiKeyResponse(e *core.RequestEvent, apiKey *core.Record, key string) error {
+	if err := apis.EnrichRecord(e, apiKey); err != nil {
+		return e.InternalServerError("failed to enrich record", err)
+	}
+
+	if key != "" {
+		apiKey.WithCustomData(true)
+		apiKey.Set("key", key)
+	}
+
+	return e.JSON(http.StatusOK, apiKey)
+}
+
+// handleCreateAPIKey creates an API key of a game, a client key unless
+// another scope is given. The plaintext key is only returned in the response.
+func handleCreateAPIKey(e *core.RequestEvent) error {
+	var body struct {
+		Label     string         `json:"label"`
+		Scope     string         `json:"scope"`
+		ExpiresAt types.DateTime `json:"expires_at"`
+	}
+	if err := e.BindBody(&body); err != nil || body.Label == "" {
+		return e.BadRequestError("a label is required", err)
+	}
+
+	body.Scope = cmp.Or(body.Scope, apiKeyScopeClient)
+	if !slices.Contains(apiKeyScopes, body.Scope) {
+		return e.BadRequestError(fmt.Sprintf("the scope must be one of %s", strings.Join(apiKeyScopes, ", ")), nil)
+	}
+
+	game, err := e.App.FindRecordById(gamesCollectionName, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("game not found", nil)
+	}
+
+	if err := requireGameRole(e, roleOwner, game.Id); err != nil {
+		return err
+	}
+
+	if !body.ExpiresAt.IsZero() && !body.ExpiresAt.Time().After(time.Now()) {
+		return e.BadRequestError("the expiry must be in the future", nil)
+	}
+
+	apiKey, key, err := newAPIKey(e.App, game.Id, body.Label, body.Scope, body.ExpiresAt, e.Auth)
+	if err != nil {
+		return e.BadRequestError("failed to create the API key", err)
+	}
+
+	return apiKeyResponse(e, apiKey, key)
+}
+
+// findManagedAPIKey returns the API key of the request path, making sure the
+// request auth record owns its game.
+func findManagedAPIKey(e *core.RequestEvent) (*core.Record, error) {
+	apiKey, err := e.App.FindRecordById(apiKeysCollectionName, e.Request.PathValue("id"))
+	if err != nil {
+		return nil, e.NotFoundError("API key not found", nil)
+	}
+
+	if err := requireGameRole(e, roleOwner, apiKey.GetString("game_id")); err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// handleRotateAPIKey revokes an API key and replaces it with a new key of the
+// same game, label, scope and expiry. The new plaintext key is only returned in the
+// response.
+func handleRotateAPIKey(e *core.RequestEvent) error {
+	apiKey, err := findManagedAPIKey(e)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := apiKey.GetDateTime("expires_at")
+	if apiKey.GetBool("revoked") || (!expiresAt.IsZero() && !expiresAt.Time().After(time.Now())) {
+		return e.BadRequestError("a revoked or expired API key can't be rotated", nil)
+	}
+
+	var next *core.Record
+	var key string
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		apiKey.Set("revoked", true)
+		if err := txApp.Save(apiKey); err != nil {
+			return err
+		}
+
+		next, key, err = newAPIKey(
+			txApp,
+			apiKey.GetString("game_id"),
+			apiKey.GetString("label"),
+			apiKeyScope(apiKey),
+			expiresAt,
+			e.Auth,
+		)
+		return err
+	})
+	if err != nil {
+		return e.BadRequestError("failed to rotate the API key", err)
+	}
+
+	return apiKeyResponse(e, next, key)
+}
+
+// handleRevokeAPIKey revokes an API key for good.
+func handleRevokeAPIKey(e *core.RequestEvent) error {
+	apiKey, err := findManagedAPIKey(e)
+	if err != nil {
+		return err
+	}
+
+	if !apiKey.GetBool("revoked") {
+		apiKey.Set("revoked", true)
+		if err := e.App.Save(apiKey); err != nil {
+			return e.BadRequestError("failed to revoke the API key", err)
+		}
+	}
+
+	return apiKeyResponse(e, apiKey, "")
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestAPIKey changes a field of the seeded API key.
+func setTestAPIKey(t testing.TB, app core.App, field string, value any) {
+	apiKey, err := app.FindRecordById(apiKeysCollectionName, testAPIKeyRecordID)
+	require.NoError(t, err)
+	apiKey.Set(field, value)
+	require.NoError(t, app.Save(apiKey))
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "revoked key",
+			Method:          http.MethodGet,
+			URL:             "/api/client-config/" + testGameID,
+			Headers:         map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				setTestAPIKey(t, app, "revoked", true)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "expired key",
+			Method:          http.MethodGet,
+			URL:             "/api/client-config/" + testGameID,
+			Headers:         map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				setTestAPIKey(t, app, "expires_at", types.NowDateTime().Add(-time.Hour))
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "key of another game",
+			Method:          http.MethodGet,
+			URL:             "/api/client-config/" + testGameID,
+			Headers:         map[string]string{clientKeyHeader: "otherkey"},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The API key doesn't grant access to this game."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				createTestRecord(t, app, gamesCollectionName, map[string]any{
+					"id":      "othergame000001",
+					"game_id": "studio.sun.other",
+				})
+				createTestRecord(t, app, apiKeysCollectionName, map[string]any{
+					"game_id":  "othergame000001",
+					"label":    "Other build",
+					"key_hash": hashAPIKey("otherkey"),
+				})
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "promotion key",
+			Method:          http.MethodGet,
+			URL:             "/api/client-config/" + testGameID,
+			Headers:         map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The client scope is required."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				setTestAPIKey(t, app, "scope", apiKeyScopePromotion)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "valid key with an expiry",
+			Method:          http.MethodGet,
+			URL:             "/api/client-config/" + testGameID,
+			Headers:         map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"game_id":"studio.sun.rpg"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				setTestAPIKey(t, app, "expires_at", types.NowDateTime().Add(time.Hour))
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				apiKey, err := app.FindRecordById(apiKeysCollectionName, testAPIKeyRecordID)
+				require.NoError(t, err)
+				assert.WithinDuration(t, time.Now(), apiKey.GetDateTime("last_used_at").Time(), time.Minute)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "API keys don't grant access to the collections",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/advertisement_configs/records",
+			Headers:         map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+// responseAPIKey decodes the API key returned by the key routes.
+func responseAPIKey(t testing.TB, res *http.Response) map[string]any {
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	var apiKey map[string]any
+	require.NoError(t, json.Unmarshal(body, &apiKey))
+
+	return apiKey
+}
+
+func TestAPIKeyRoutes(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "creating a key requires authentication",
+			Method:          http.MethodPost,
+			URL:             "/api/games/" + testGameRecordID + "/api-keys",
+			Body:            strings.NewReader(`{"label":"iOS build"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "creating a key requires the owner role",
+			Method:          http.MethodPost,
+			URL:             "/api/games/" + testGameRecordID + "/api-keys",
+			Body:            strings.NewReader(`{"label":"iOS build"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The owner role is required in the game " + testGameRecordID + "."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, rolePublisher)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "a key requires a label",
+			Method:          http.MethodPost,
+			URL:             "/api/games/" + testGameRecordID + "/api-keys",
+			Body:            strings.NewReader(`{}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"A label is required."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "a key requires a valid scope",
+			Method:          http.MethodPost,
+			URL:             "/api/games/" + testGameRecordID + "/api-keys",
+			Body:            strings.NewReader(`{"label":"Production","scope":"admin"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"The scope must be one of client, promotion."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "creating a promotion key",
+			Method:          http.MethodPost,
+			URL:             "/api/games/" + testGameRecordID + "/api-keys",
+			Body:            strings.NewReader(`{"label":"Production","scope":"promotion"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"scope":"promotion"`, `"key":"cm_`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:               "the plaintext key is returned once on creation",
+			Method:             http.MethodPost,
+			URL:                "/api/games/" + testGameRecordID + "/api-keys",
+			Body:               strings.NewReader(`{"label":"iOS build"}`),
+			Headers:            map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:     200,
+			ExpectedContent:    []string{`"label":"iOS build"`, `"key":"cm_`, `"revoked":false`, `"scope":"client"`},
+			NotExpectedContent: []string{"key_hash"},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, roleOwner)
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				created := responseAPIKey(t, res)
+
+				apiKey, err := findAPIKey(app, created["key"].(string), time.Now())
+				require.NoError(t, err)
+				assert.Equal(t, testGameRecordID, apiKey.GetString("game_id"))
+				assert.Equal(t, created["key_prefix"], created["key"].(string)[:apiKeyPrefixLength])
+
+				user, err := app.FindAuthRecordByEmail(usersCollectionName, userEmail)
+				require.NoError(t, err)
+				assert.Equal(t, user.Id, apiKey.GetString("created_by"))
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "rotating a key revokes it",
+			Method:          http.MethodPost,
+			URL:             "/api/api-keys/" + testAPIKeyRecordID + "/rotate",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"label":"Test build"`, `"key":"cm_`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				rotated := responseAPIKey(t, res)
+				assert.NotEqual(t, testAPIKeyRecordID, rotated["id"])
+				assert.Equal(t, apiKeyScopeClient, rotated["scope"])
+
+				_, err := findAPIKey(app, testClientKey, time.Now())
+				assert.Error(t, err, "The previous key should be revoked")
+
+				_, err = findAPIKey(app, rotated["key"].(string), time.Now())
+				assert.NoError(t, err)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "revoked keys can't be rotated",
+			Method:          http.MethodPost,
+			URL:             "/api/api-keys/" + testAPIKeyRecordID + "/rotate",
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"A revoked or expired API key can't be rotated."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				setTestAPIKey(t, app, "revoked", true)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:               "revoking a key",
+			Method:             http.MethodPost,
+			URL:                "/api/api-keys/" + testAPIKeyRecordID + "/revoke",
+			ExpectedStatus:     200,
+			ExpectedContent:    []string{`"id":"` + testAPIKeyRecordID + `"`, `"revoked":true`},
+			NotExpectedContent: []string{`"key":`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, roleOwner)
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				_, err := findAPIKey(app, testClientKey, time.Now())
+				assert.Error(t, err)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "revoking a key requires the owner role",
+			Method:          http.MethodPost,
+			URL:             "/api/api-keys/" + testAPIKeyRecordID + "/revoke",
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The owner role is required in the game " + testGameRecordID + "."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, roleEditor)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
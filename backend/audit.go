@@ -0,0 +1,394 @@
+package main
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	auditActionCreate = "create"
+	auditActionUpdate = "update"
+	auditActionDelete = "delete"
+)
+
+// auditedCollections lists the collections whose changes are recorded in the
+// audit log.
+var auditedCollections = []string{
+	gamesCollectionName,
+	advertisementConfigsCollectionName,
+	advertisementsPlacementsCollectionName,
+}
+
+// auditChange is the before and after value of a changed field.
+type auditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// auditChanges returns the field-level diff between two states of a record,
+// the before state being nil on create and the after state nil on delete.
+// The system and hidden fields are ignored, as well as the empty fields of
+// the created and deleted records.
+func auditChanges(before *core.Record, after *core.Record) map[string]auditChange {
+	changes := map[string]auditChange{}
+
+	record := cmp.Or(after, before)
+	for _, field := range record.Collection().Fields {
+		name := field.GetName()
+		if slices.Contains(systemFields, name) || field.GetHidden() || field.Type() == core.FieldTypeAutodate {
+			continue
+		}
+
+		var change auditChange
+		if before != nil {
+			change.Before = before.Get(name)
+		}
+		if after != nil {
+			change.After = after.Get(name)
+		}
+
+		if before != nil && after != nil {
+			if recordValuesEqual(change.Before, change.After) {
+				continue
+			}
+		} else if isEmptyAuditValue(change.Before) && isEmptyAuditValue(change.After) {
+			continue
+		}
+
+		changes[name] = change
+	}
+
+	return changes
+}
+
+func isEmptyAuditValue(value any) bool {
+	if raw, ok := value.(types.JSONRaw); ok {
+		return len(raw) == 0 || string(raw) == "null"
+	}
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	return v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0)
+}
+
+// writeAuditEntry records a change of a record made by the request auth
+// record, or by the server itself when there is no request (e.g. the import
+// command). Updates that don't change any field aren't recorded.
+func writeAuditEntry(app core.App, e *core.RequestEvent, action string, before *core.Record, after *core.Record, gameIDs []string) error {
+	changes := auditChanges(before, after)
+	if action == auditActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	collection, err := app.FindCollectionByNameOrId(auditLogCollectionName)
+	if err != nil {
+		return err
+	}
+
+	record := cmp.Or(after, before)
+
+	entry := core.NewRecord(collection)
+	if e != nil && e.Auth != nil {
+		entry.Set("actor", e.Auth.Id)
+		entry.Set("actor_email", e.Auth.Email())
+	}
+	if e != nil {
+		entry.Set("ip", e.RealIP())
+	}
+	entry.Set("action", action)
+	entry.Set("collection", record.Collection().Name)
+	entry.Set("record_id", record.Id)
+	entry.Set("game_ids", strings.Join(gameIDs, ","))
+	entry.Set("fields", strings.Join(slices.Sorted(maps.Keys(changes)), ","))
+	entry.Set("changes", changes)
+
+	return app.Save(entry)
+}
+
+// auditRecordRequest records the change made by a record request in the
+// audit log, within the same transaction.
+//
+// On update, the after state is the record persisted by the request, which
+// is the new version of the versioned records.
+func auditRecordRequest(e *core.RecordRequestEvent, action string) error {
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		e.App = txApp
+
+		var before *core.Record
+		var gameIDs []string
+		var dependents []auditedDependent
+		switch action {
+		case auditActionUpdate:
+			before = e.Record.Original()
+		case auditActionDelete:
+			before = e.Record
+
+			// the games have to be resolved while the parents still exist
+			ids, err := recordGameIDs(txApp, before)
+			if err != nil {
+				return err
+			}
+			gameIDs = ids
+
+			dependents, err = findAuditedDependents(txApp, before)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		if err := auditDependentChanges(txApp, e.RequestEvent, dependents); err != nil {
+			return err
+		}
+
+		var after *core.Record
+		if action != auditActionDelete {
+			after = e.Record
+
+			ids, err := recordGameIDs(txApp, after)
+			if err != nil {
+				return err
+			}
+			gameIDs = ids
+			if before != nil {
+				beforeIDs, err := recordGameIDs(txApp, before)
+				if err != nil {
+					return err
+				}
+				for _, id := range beforeIDs {
+					if !slices.Contains(gameIDs, id) {
+						gameIDs = append(gameIDs, id)
+					}
+				}
+			}
+		}
+
+		return writeAuditEntry(txApp, e.RequestEvent, action, before, after, gameIDs)
+	})
+}
+
+// auditedDependent is an audited record which may be deleted or changed
+// along with the record it depends on, with the games it belonged to.
+type auditedDependent struct {
+	record  *core.Record
+	gameIDs []string
+}
+
+// findAuditedDependents returns the audited records deleted (or changed) by
+// the deletion of the record: the advertisement configs of a game, which
+// are deleted or just lose the game when they are shared by other games, the
+// other versions of an advertisement config (see deleteVersionLineage) and
+// the placements of all of these configs.
+func findAuditedDependents(app core.App, record *core.Record) ([]auditedDependent, error) {
+	var configs []*core.Record
+	var configIDs []any
+	switch record.Collection().Name {
+	case gamesCollectionName:
+		found, err := app.FindRecordsByFilter(
+			advertisementConfigsCollectionName,
+			"game_id.id ?= {:game}",
+			"",
+			0,
+			0,
+			dbx.Params{"game": record.Id},
+		)
+		if err != nil {
+			return nil, err
+		}
+		configs = found
+	case advertisementConfigsCollectionName:
+		found, err := app.FindAllRecords(
+			advertisementConfigsCollectionName,
+			versionLineage(record),
+			dbx.Not(dbx.HashExp{"id": record.Id}),
+		)
+		if err != nil {
+			return nil, err
+		}
+		configs = found
+		configIDs = append(configIDs, record.Id)
+	default:
+		return nil, nil
+	}
+
+	for _, config := range configs {
+		configIDs = append(configIDs, config.Id)
+	}
+	if len(configIDs) == 0 {
+		return nil, nil
+	}
+
+	placements, err := app.FindAllRecords(
+		advertisementsPlacementsCollectionName,
+		dbx.In("advertisement_id", configIDs...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dependents := make([]auditedDependent, 0, len(configs)+len(placements))
+	for _, dependent := range append(configs, placements...) {
+		gameIDs, err := recordGameIDs(app, dependent)
+		if err != nil {
+			return nil, err
+		}
+		dependents = append(dependents, auditedDependent{dependent, gameIDs})
+	}
+
+	return dependents, nil
+}
+
+// auditDependentChanges records the deletion or the change of the
+// dependents of a deleted record made by the request auth record.
+func auditDependentChanges(app core.App, e *core.RequestEvent, dependents []auditedDependent) error {
+	for _, dependent := range dependents {
+		action := auditActionUpdate
+		after, err := app.FindRecordById(dependent.record.Collection(), dependent.record.Id)
+		if errors.Is(err, sql.ErrNoRows) {
+			action = auditActionDelete
+		} else if err != nil {
+			return err
+		}
+
+		if err := writeAuditEntry(app, e, action, dependent.record, after, dependent.gameIDs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func auditRecordCreate(e *core.RecordRequestEvent) error {
+	return auditRecordRequest(e, auditActionCreate)
+}
+
+func auditRecordUpdate(e *core.RecordRequestEvent) error {
+	return auditRecordRequest(e, auditActionUpdate)
+}
+
+func auditRecordDelete(e *core.RecordRequestEvent) error {
+	return auditRecordRequest(e, auditActionDelete)
+}
+
+// preventAuditLogChanges keeps the audit log append-only, even for the
+// superusers who bypass the collection rules.
+func preventAuditLogChanges(e *core.RecordRequestEvent) error {
+	return e.ForbiddenError("the audit log entries are written by the server and can't be changed", nil)
+}
+
+const (
+	auditLogDefaultPerPage = 30
+	auditLogMaxPerPage     = 500
+)
+
+type auditLogPage struct {
+	Items      []*core.Record `json:"items"`
+	Page       int            `json:"page"`
+	PerPage    int            `json:"perPage"`
+	TotalItems int            `json:"totalItems"`
+	TotalPages int            `json:"totalPages"`
+}
+
+// listContains matches the rows whose comma separated list column contains
+// the value.
+func listContains(column string, value string, param string) dbx.Expression {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+
+	return dbx.NewExp(
+		"(',' || "+column+" || ',') LIKE {:"+param+"} ESCAPE '\\'",
+		dbx.Params{param: "%," + escaped + ",%"},
+	)
+}
+
+// handleAuditLog serves the audit log entries, most recent first. The
+// entries can be filtered by collection, record_id, actor, action, changed
+// field, game (record id) and creation date range (from inclusive, to
+// exclusive). Users only get the entries of the games they are a member of.
+func handleAuditLog(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	page = max(page, 1)
+	perPage, _ := strconv.Atoi(query.Get("perPage"))
+	if perPage <= 0 {
+		perPage = auditLogDefaultPerPage
+	}
+	perPage = min(perPage, auditLogMaxPerPage)
+
+	var conditions []dbx.Expression
+	for _, name := range []string{"collection", "record_id", "actor", "action"} {
+		if value := query.Get(name); value != "" {
+			conditions = append(conditions, dbx.HashExp{name: value})
+		}
+	}
+	if field := query.Get("field"); field != "" {
+		conditions = append(conditions, listContains("fields", field, "field"))
+	}
+	if game := query.Get("game"); game != "" {
+		conditions = append(conditions, listContains("game_ids", game, "game"))
+	}
+	for param, operator := range map[string]string{"from": ">=", "to": "<"} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+
+		date, err := types.ParseDateTime(value)
+		if err != nil || date.IsZero() {
+			return e.BadRequestError(fmt.Sprintf("invalid %s date", param), err)
+		}
+		conditions = append(conditions, dbx.NewExp("created "+operator+" {:"+param+"}", dbx.Params{param: date.String()}))
+	}
+
+	if !e.HasSuperuserAuth() {
+		games, err := findMemberGames(e)
+		if err != nil {
+			return e.InternalServerError("failed to find the games", err)
+		}
+
+		scope := []dbx.Expression{dbx.NewExp("1 = 0")}
+		for i, game := range games {
+			scope = append(scope, listContains("game_ids", game.Id, fmt.Sprintf("member%d", i)))
+		}
+		conditions = append(conditions, dbx.Or(scope...))
+	}
+
+	where := dbx.And(conditions...)
+
+	result := auditLogPage{Items: []*core.Record{}, Page: page, PerPage: perPage}
+
+	err := e.App.DB().Select("count(*)").From(auditLogCollectionName).Where(where).Row(&result.TotalItems)
+	if err != nil {
+		return e.InternalServerError("failed to count the audit log entries", err)
+	}
+	result.TotalPages = (result.TotalItems + perPage - 1) / perPage
+
+	err = e.App.RecordQuery(auditLogCollectionName).
+		Where(where).
+		OrderBy("created DESC", "id DESC").
+		Limit(int64(perPage)).
+		Offset(int64((page - 1) * perPage)).
+		All(&result.Items)
+	if err != nil {
+		return e.InternalServerError("failed to list the audit log entries", err)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
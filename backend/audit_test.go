@@ -0,0 +1,422 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findAuditEntries returns the audit log entries of a collection.
+func findAuditEntries(t testing.TB, app core.App, collection string) []*core.Record {
+	entries, err := app.FindAllRecords(auditLogCollectionName, dbx.HashExp{"collection": collection})
+	require.NoError(t, err)
+
+	return entries
+}
+
+// findRecordAuditEntry returns the single audit log entry of the record.
+func findRecordAuditEntry(t testing.TB, app core.App, recordID string) *core.Record {
+	entries, err := app.FindAllRecords(auditLogCollectionName, dbx.HashExp{"record_id": recordID})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	return entries[0]
+}
+
+// seedAuditEntry writes an audit log entry of a change of the seeded
+// advertisement config by the admin.
+func seedAuditEntry(t testing.TB, app core.App, field string) {
+	admin, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, adminEmail)
+	require.NoError(t, err)
+
+	createTestRecord(t, app, auditLogCollectionName, map[string]any{
+		"actor":       admin.Id,
+		"actor_email": adminEmail,
+		"action":      auditActionUpdate,
+		"collection":  advertisementConfigsCollectionName,
+		"record_id":   testConfigRecordID,
+		"game_ids":    testGameRecordID,
+		"fields":      field,
+		"changes":     map[string]auditChange{field: {Before: 1, After: 2}},
+	})
+}
+
+func TestAuditLogHooks(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:           "updates record the changed fields",
+			Method:         http.MethodPatch,
+			URL:            "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			Body:           strings.NewReader(`{"banner_refresh_rate":90}`),
+			Headers:        map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"banner_refresh_rate":90`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				entries := findAuditEntries(t, app, advertisementConfigsCollectionName)
+				require.Len(t, entries, 1)
+
+				admin, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, adminEmail)
+				require.NoError(t, err)
+
+				entry := entries[0]
+				assert.Equal(t, auditActionUpdate, entry.GetString("action"))
+				assert.Equal(t, admin.Id, entry.GetString("actor"))
+				assert.Equal(t, adminEmail, entry.GetString("actor_email"))
+				assert.Equal(t, testGameRecordID, entry.GetString("game_ids"))
+				assert.NotEmpty(t, entry.GetString("ip"))
+
+				// the change is saved as a new version (see createVersionOnUpdate)
+				assert.NotEqual(t, testConfigRecordID, entry.GetString("record_id"))
+				assert.Contains(t, strings.Split(entry.GetString("fields"), ","), "banner_refresh_rate")
+				assert.Contains(t, entry.GetString("changes"), `"banner_refresh_rate":{"before":60,"after":90}`)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "creations record the set fields",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/games/records",
+			Body:            strings.NewReader(`{"game_id":"studio.sun.new","name":"New"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"game_id":"studio.sun.new"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				entries := findAuditEntries(t, app, gamesCollectionName)
+				require.Len(t, entries, 1)
+
+				game, err := app.FindFirstRecordByData(gamesCollectionName, "game_id", "studio.sun.new")
+				require.NoError(t, err)
+
+				entry := entries[0]
+				assert.Equal(t, auditActionCreate, entry.GetString("action"))
+				assert.Equal(t, game.Id, entry.GetString("record_id"))
+				assert.Equal(t, game.Id, entry.GetString("game_ids"))
+				assert.Equal(t, userEmail, entry.GetString("actor_email"))
+				assert.Equal(t, "game_id,name,status", entry.GetString("fields"))
+				assert.Contains(t, entry.GetString("changes"), `"name":{"before":null,"after":"New"}`)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "deletions record the previous values",
+			Method:         http.MethodDelete,
+			URL:            "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			ExpectedStatus: 204,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, roleEditor)
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				entries := findAuditEntries(t, app, advertisementsPlacementsCollectionName)
+				require.Len(t, entries, 1)
+
+				entry := entries[0]
+				assert.Equal(t, auditActionDelete, entry.GetString("action"))
+				assert.Equal(t, testGameRecordID, entry.GetString("game_ids"))
+				assert.Contains(t, entry.GetString("changes"), `"min_level":{"before":3,"after":null}`)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "rejected changes aren't recorded",
+			Method:          http.MethodPatch,
+			URL:             "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			Body:            strings.NewReader(`{"banner_refresh_rate":90}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The editor role is required"},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, roleViewer)
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				assert.Empty(t, findAuditEntries(t, app, advertisementConfigsCollectionName))
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "rollbacks are recorded",
+			Method:          http.MethodPost,
+			URL:             "/api/configs/" + testSecondConfigRecordID + "/rollback",
+			Body:            strings.NewReader(`{"version":1}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"version":3`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedSecondConfigVersion(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				assert.Len(t, findAuditEntries(t, app, advertisementConfigsCollectionName), 2)
+
+				rollback := findRecordAuditEntry(t, app, findLatestTestConfig(t, app).Id)
+				assert.Equal(t, auditActionUpdate, rollback.GetString("action"))
+				assert.Contains(t, rollback.GetString("changes"), `"version":{"before":2,"after":3}`)
+
+				archived := findRecordAuditEntry(t, app, testSecondConfigRecordID)
+				assert.Contains(t, archived.GetString("changes"), `"status":{"before":"published","after":"archived"}`,
+					"The republished rollback should archive the published version")
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "placement changes record the new config version",
+			Method:          http.MethodPatch,
+			URL:             "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			Body:            strings.NewReader(`{"min_level":5}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"min_level":5`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, roleEditor)
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				latest := findLatestTestConfig(t, app)
+
+				entries := findAuditEntries(t, app, advertisementConfigsCollectionName)
+				require.Len(t, entries, 1)
+				assert.Equal(t, auditActionUpdate, entries[0].GetString("action"))
+				assert.Equal(t, latest.Id, entries[0].GetString("record_id"))
+				assert.Equal(t, userEmail, entries[0].GetString("actor_email"))
+				assert.Equal(t, testGameRecordID, entries[0].GetString("game_ids"))
+				assert.Contains(t, entries[0].GetString("changes"), `"version":{"before":1,"after":2}`)
+
+				assert.Len(t, findAuditEntries(t, app, advertisementsPlacementsCollectionName), 1)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "publishing records the archived versions",
+			Method:          http.MethodPatch,
+			URL:             "/api/collections/advertisement_configs/records/" + testSecondConfigRecordID,
+			Body:            strings.NewReader(`{"status":"published"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"status":"published"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigWithStatus(t, app, statusApproved)
+				first, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+				require.NoError(t, err)
+				first.Set("status", statusPublished)
+				require.NoError(t, app.Save(first))
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				assert.Len(t, findAuditEntries(t, app, advertisementConfigsCollectionName), 2)
+
+				archived := findRecordAuditEntry(t, app, testConfigRecordID)
+				assert.Equal(t, adminEmail, archived.GetString("actor_email"))
+				assert.Equal(t, testGameRecordID, archived.GetString("game_ids"))
+				assert.Equal(t, "status", archived.GetString("fields"))
+				assert.Contains(t, archived.GetString("changes"), `"status":{"before":"published","after":"archived"}`)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "deletions record the cascaded deletions",
+			Method:         http.MethodDelete,
+			URL:            "/api/collections/games/records/" + testGameRecordID,
+			ExpectedStatus: 204,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedSecondConfigVersion(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				assert.Len(t, findAuditEntries(t, app, gamesCollectionName), 1)
+
+				configEntries := findAuditEntries(t, app, advertisementConfigsCollectionName)
+				assert.Len(t, configEntries, 2, "Every version of the game config should be recorded")
+				placementEntries := findAuditEntries(t, app, advertisementsPlacementsCollectionName)
+				assert.Len(t, placementEntries, 2)
+
+				for _, entry := range append(configEntries, placementEntries...) {
+					assert.Equal(t, auditActionDelete, entry.GetString("action"))
+					assert.Equal(t, adminEmail, entry.GetString("actor_email"))
+					assert.Equal(t, testGameRecordID, entry.GetString("game_ids"))
+				}
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "entries can't be created through the API",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/audit_log/records",
+			Body:            strings.NewReader(`{"action":"update","collection":"games","record_id":"forged"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The audit log entries are written by the server and can't be changed."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "entries can't be deleted, even by superusers",
+			Method:          http.MethodDelete,
+			URL:             "/api/collections/audit_log/records/testauditlog001",
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The audit log entries are written by the server and can't be changed."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				createTestRecord(t, app, auditLogCollectionName, map[string]any{
+					"id":         "testauditlog001",
+					"action":     auditActionUpdate,
+					"collection": gamesCollectionName,
+					"record_id":  testGameRecordID,
+				})
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "users can't read the collection directly",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/audit_log/records",
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestAuditLogEndpoint(t *testing.T) {
+	seed := func(t testing.TB, app core.App) {
+		seedClientConfig(t, app)
+		seedAuditEntry(t, app, "banner_refresh_rate")
+		seedAuditEntry(t, app, "banner_position")
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "requires authentication",
+			Method:          http.MethodGet,
+			URL:             "/api/audit-log",
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:            "filter by changed field",
+			Method:          http.MethodGet,
+			URL:             "/api/audit-log?field=banner_refresh_rate&collection=advertisement_configs",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":1`, `"fields":"banner_refresh_rate"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "the field filter matches whole names",
+			Method:          http.MethodGet,
+			URL:             "/api/audit-log?field=banner",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`, `"items":[]`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "pagination",
+			Method:          http.MethodGet,
+			URL:             "/api/audit-log?perPage=1&page=2",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"page":2,"perPage":1,"totalItems":2,"totalPages":2`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "invalid date range",
+			Method:          http.MethodGet,
+			URL:             "/api/audit-log?from=yesterday",
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"Invalid from date."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "date range",
+			Method:          http.MethodGet,
+			URL:             "/api/audit-log?from=2000-01-01&to=2001-01-01",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "users only get the entries of their games",
+			Method:          http.MethodGet,
+			URL:             "/api/audit-log",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":0`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "members get the entries of their games",
+			Method:          http.MethodGet,
+			URL:             "/api/audit-log?game=" + testGameRecordID,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"totalItems":2`, `"actor_email":"` + adminEmail + `"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app)
+				seedGameMember(t, app, roleViewer)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"config-manager/adtypes"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// clientKeyHeader is the request header carrying the game API key.
+const clientKeyHeader = "X-Client-Key"
+
+var errNoClientConfig = errors.New("no advertisement config found for game")
+
+// clientConfig is the denormalized advertisement configuration document
+// served to the game clients.
+type clientConfig struct {
+	GameID              string                     `json:"game_id"`
+	ConfigID            string                     `json:"config_id"`
+	Name                string                     `json:"name"`
+	ExperimentID        string                     `json:"experiment_id"`
+	Variant             string                     `json:"variant,omitempty"`
+	AdUnits             clientAdUnits              `json:"ad_units"`
+	Banner              clientBannerSettings       `json:"banner"`
+	PreloadInterstitial bool                       `json:"preload_interstitial"`
+	PreloadRewarded     bool                       `json:"preload_rewarded"`
+	EnableConsentFlow   bool                       `json:"enable_consent_flow"`
+	Placements          map[string]clientPlacement `json:"placements"`
+	Updated             string                     `json:"updated"`
+}
+
+type clientAdUnits struct {
+	Banner       string `json:"banner"`
+	Interstitial string `json:"interstitial"`
+	Rewarded     string `json:"rewarded"`
+}
+
+type clientBannerSettings struct {
+	AutoHide           bool    `json:"auto_hide"`
+	Position           int     `json:"position"`
+	PositionName       string  `json:"position_name"`
+	RefreshRate        float64 `json:"refresh_rate"`
+	MemoryThreshold    float64 `json:"memory_threshold"`
+	DestroyOnLowMemory bool    `json:"destroy_on_low_memory"`
+}
+
+type clientPlacement struct {
+	AdFormat       int     `json:"ad_format"`
+	AdFormatName   string  `json:"ad_format_name"`
+	Action         int     `json:"action"`
+	ActionName     string  `json:"action_name"`
+	MinLevel       int     `json:"min_level"`
+	TimeBetween    float64 `json:"time_between"`
+	ShowLoading    bool    `json:"show_loading"`
+	TimeOut        float64 `json:"time_out"`
+	Retry          int     `json:"retry"`
+	ShowAdNotice   bool    `json:"show_ad_notice"`
+	DelayTime      float64 `json:"delay_time"`
+	CustomAdUnitID string  `json:"custom_ad_unit_id,omitempty"`
+}
+
+// handleClientConfig serves the resolved advertisement configuration of a
+// single game, identified by its game_id or one of its store identifiers.
+// It doesn't require an auth record, only a client API key of the game (see
+// requireGameAPIKey).
+func handleClientConfig(e *core.RequestEvent) error {
+	game, ok := e.Get(clientGameContextKey).(*core.Record)
+	if !ok {
+		return e.UnauthorizedError("missing or invalid API key", nil)
+	}
+
+	config, err := resolveClientConfig(e.App, game, newClientContext(e.Request.URL.Query()))
+	if err != nil {
+		if errors.Is(err, errNoClientConfig) {
+			return e.NotFoundError(err.Error(), nil)
+		}
+		return e.InternalServerError("failed to resolve client config", err)
+	}
+
+	return e.JSON(http.StatusOK, config)
+}
+
+// resolveClientConfig picks the advertisement config of the game (considering
+// only the published versions) targeting the client with the highest
+// precedence (see selectTargetedConfigs) and combines it with its placements.
+//
+// When the picked config takes part in a running experiment and a player id
+// is given, the player is assigned to one of the matching experiment
+// variants instead.
+func resolveClientConfig(app core.App, game *core.Record, client clientContext) (*clientConfig, error) {
+	gameID := game.GetString("game_id")
+	if client.Now.IsZero() {
+		client.Now = time.Now()
+	}
+
+	configs, err := app.FindRecordsByFilter(
+		advertisementConfigsCollectionName,
+		"status = {:status} && game_id.id ?= {:game}",
+		"-updated",
+		0,
+		0,
+		dbx.Params{"status": statusPublished, "game": game.Id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := selectTargetedConfigs(configs, client)
+	if len(candidates) == 0 {
+		return nil, errNoClientConfig
+	}
+
+	adConfig := candidates[0]
+	variant := ""
+
+	experimentKey := adConfig.GetString("experiment_id")
+	experiment, err := findRunningExperiment(app, game, experimentKey)
+	if err != nil {
+		return nil, err
+	}
+	if experiment != nil && client.PlayerID != "" {
+		variants := slices.DeleteFunc(slices.Clone(candidates), func(config *core.Record) bool {
+			return config.GetString("experiment_id") != experimentKey
+		})
+		if assigned := assignVariant(experimentKey, client.PlayerID, variants); assigned != nil {
+			adConfig = assigned
+			variant = assigned.GetString("name")
+		}
+	}
+
+	placements, err := app.FindAllRecords(
+		advertisementsPlacementsCollectionName,
+		dbx.HashExp{"advertisement_id": adConfig.Id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := placementNames(app, placements)
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildClientConfig(gameID, adConfig, placements, names)
+	result.Variant = variant
+
+	return result, nil
+}
+
+// configGameIDs returns the record ids of the games an advertisement config
+// is assigned to.
+func configGameIDs(config *core.Record) []string {
+	return config.GetStringSlice("game_id")
+}
+
+// buildClientConfig combines the advertisement config with its placements,
+// keyed by their catalog names.
+func buildClientConfig(gameID string, adConfig *core.Record, placements []*core.Record, names map[string]string) *clientConfig {
+	result := &clientConfig{
+		GameID:       gameID,
+		ConfigID:     adConfig.Id,
+		Name:         adConfig.GetString("name"),
+		ExperimentID: adConfig.GetString("experiment_id"),
+		AdUnits: clientAdUnits{
+			Banner:       adConfig.GetString("banner_ad_unit_id"),
+			Interstitial: adConfig.GetString("interstitial_ad_unit_id"),
+			Rewarded:     adConfig.GetString("rewarded_ad_unit_id"),
+		},
+		Banner: clientBannerSettings{
+			AutoHide:           adConfig.GetBool("auto_hide_banner"),
+			Position:           adConfig.GetInt("banner_position"),
+			PositionName:       adtypes.BannerPosition(adConfig.GetInt("banner_position")).String(),
+			RefreshRate:        adConfig.GetFloat("banner_refresh_rate"),
+			MemoryThreshold:    adConfig.GetFloat("banner_memory_threshold"),
+			DestroyOnLowMemory: adConfig.GetBool("destroy_banner_on_low_memory"),
+		},
+		PreloadInterstitial: adConfig.GetBool("preload_interstitial"),
+		PreloadRewarded:     adConfig.GetBool("preload_rewarded"),
+		EnableConsentFlow:   adConfig.GetBool("enable_consent_flow"),
+		Placements:          make(map[string]clientPlacement, len(placements)),
+		Updated:             adConfig.GetDateTime("updated").String(),
+	}
+
+	for _, placement := range placements {
+		result.Placements[names[placement.Id]] = clientPlacement{
+			AdFormat:       placement.GetInt("ad_format"),
+			AdFormatName:   adtypes.AdFormat(placement.GetInt("ad_format")).String(),
+			Action:         placement.GetInt("action"),
+			ActionName:     adtypes.Action(placement.GetInt("action")).String(),
+			MinLevel:       placement.GetInt("min_level"),
+			TimeBetween:    placement.GetFloat("time_between"),
+			ShowLoading:    placement.GetBool("show_loading"),
+			TimeOut:        placement.GetFloat("time_out"),
+			Retry:          placement.GetInt("retry"),
+			ShowAdNotice:   placement.GetBool("show_ad_notice"),
+			DelayTime:      placement.GetFloat("delay_time"),
+			CustomAdUnitID: placement.GetString("custom_ad_unit_id"),
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testGameID         = "studio.sun.rpg"
+	testClientKey      = "abcdefghijklmnopqrstuvwxyz012345"
+	testAPIKeyRecordID = "testapikey00001"
+
+	testPlacementRecordID = "testplacement01"
+)
+
+// createTestRecord saves a new record with the given data into the test app.
+func createTestRecord(t testing.TB, app core.App, collectionName string, data map[string]any) *core.Record {
+	collection, err := app.FindCollectionByNameOrId(collectionName)
+	require.NoError(t, err, "Failed to find collection %s", collectionName)
+
+	record := core.NewRecord(collection)
+	record.Load(data)
+	require.NoError(t, app.Save(record), "Failed to create %s record", collectionName)
+
+	return record
+}
+
+// findTestPlacementID returns the id of the global catalog placement with the given name.
+func findTestPlacementID(t testing.TB, app core.App, name string) string {
+	placement, err := app.FindFirstRecordByFilter(
+		placementsCollectionName,
+		"name = {:name} && game_id = ''",
+		dbx.Params{"name": name},
+	)
+	require.NoError(t, err, "Failed to find placement %s", name)
+
+	return placement.Id
+}
+
+// seedClientConfig creates a game with an API key, one advertisement config
+// and two placements.
+func seedClientConfig(t testing.TB, app core.App) *core.Record {
+	createTestRecord(t, app, gamesCollectionName, map[string]any{
+		"id":      testGameRecordID,
+		"game_id": testGameID,
+	})
+	createTestRecord(t, app, apiKeysCollectionName, map[string]any{
+		"id":         testAPIKeyRecordID,
+		"game_id":    testGameRecordID,
+		"label":      "Test build",
+		"key_hash":   hashAPIKey(testClientKey),
+		"key_prefix": testClientKey[:apiKeyPrefixLength],
+	})
+
+	config := createTestRecord(t, app, advertisementConfigsCollectionName, map[string]any{
+		"id":                      testConfigRecordID,
+		"lineage_id":              testConfigRecordID,
+		"version":                 1,
+		"is_latest":               true,
+		"status":                  statusPublished,
+		"name":                    "default",
+		"experiment_id":           "control",
+		"game_id":                 []string{testGameRecordID},
+		"banner_ad_unit_id":       "banner-unit",
+		"interstitial_ad_unit_id": "interstitial-unit",
+		"rewarded_ad_unit_id":     "rewarded-unit",
+		"banner_position":         1,
+		"banner_refresh_rate":     60,
+	})
+
+	createTestRecord(t, app, advertisementsPlacementsCollectionName, map[string]any{
+		"id":               testPlacementRecordID,
+		"advertisement_id": config.Id,
+		"placement_id":     findTestPlacementID(t, app, "AppReady"),
+		"ad_format":        1,
+		"min_level":        3,
+	})
+	createTestRecord(t, app, advertisementsPlacementsCollectionName, map[string]any{
+		"advertisement_id": config.Id,
+		"placement_id":     findTestPlacementID(t, app, "Button/Hint/Click"),
+		"ad_format":        2,
+		"retry":            2,
+	})
+
+	return config
+}
+
+func TestClientConfigEndpoint(t *testing.T) {
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedClientConfig(t, app)
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "unknown game",
+			Method:          http.MethodGet,
+			URL:             "/api/client-config/studio.sun.unknown",
+			Headers:         map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:            "missing client key",
+			Method:          http.MethodGet,
+			URL:             "/api/client-config/" + testGameID,
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:            "invalid client key",
+			Method:          http.MethodGet,
+			URL:             "/api/client-config/" + testGameID,
+			Headers:         map[string]string{clientKeyHeader: "invalid_key"},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:           "valid client key",
+			Method:         http.MethodGet,
+			URL:            "/api/client-config/" + testGameID,
+			Headers:        map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"game_id":"studio.sun.rpg"`,
+				`"ad_units":{"banner":"banner-unit","interstitial":"interstitial-unit","rewarded":"rewarded-unit"}`,
+				`"refresh_rate":60`,
+				`"position":1,"position_name":"bottom"`,
+				`"AppReady":{"ad_format":1,"ad_format_name":"interstitial","action":0,"action_name":"show","min_level":3`,
+				`"Button/Hint/Click":{"ad_format":2,"ad_format_name":"rewarded"`,
+			},
+			NotExpectedContent: []string{testClientKey},
+			BeforeTestFunc:     seed,
+			TestAppFactory:     setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestAdConfigGames(t *testing.T) {
+	app := setupTestApp(t)
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+	require.NoError(t, err)
+	assert.IsType(t, &core.RelationField{}, collection.Fields.GetByName("game_id"))
+
+	seedClientConfig(t, app)
+	other := createTestRecord(t, app, gamesCollectionName, map[string]any{
+		"game_id": "studio.sun.other",
+	})
+	shared := createTestRecord(t, app, advertisementConfigsCollectionName, map[string]any{
+		"name":          "shared",
+		"experiment_id": "control",
+		"game_id":       []string{testGameRecordID, other.Id},
+	})
+
+	game, err := app.FindRecordById(gamesCollectionName, testGameRecordID)
+	require.NoError(t, err)
+	require.NoError(t, app.Delete(game))
+
+	_, err = app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+	assert.Error(t, err, "Configs of the deleted game only should be deleted")
+
+	shared, err = app.FindRecordById(advertisementConfigsCollectionName, shared.Id)
+	require.NoError(t, err, "Configs shared with another game should be kept")
+	assert.Equal(t, []string{other.Id}, configGameIDs(shared))
+}
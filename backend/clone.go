@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// cloneAdvertisementConfig saves a copy of the advertisement config, with the
+// given field overrides, as the first version of a new lineage along with a
+// copy of all of its placements.
+//
+// The copy starts as a draft authored by the given user. Unlike the request
+// hooks, the records are validated here, the placements errors being keyed
+// by the id of the placement they were copied from. It returns the new
+// config and its placements indexed by the id of the placement they were
+// copied from.
+//
+// It must be called inside a transaction.
+func cloneAdvertisementConfig(txApp core.App, config *core.Record, overrides map[string]any, author *core.Record) (*core.Record, map[string]*core.Record, error) {
+	clone := copyRecord(config)
+	for field, value := range overrides {
+		clone.Set(field, value)
+	}
+	clone.Id = core.GenerateDefaultRandomId()
+	clone.Set("lineage_id", clone.Id)
+	startDraft(clone, author)
+
+	if err := prepareNextVersion(txApp, clone); err != nil {
+		return nil, nil, err
+	}
+
+	errs, err := recordValidationErrors(txApp, clone)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	if err := txApp.Save(clone); err != nil {
+		return nil, nil, err
+	}
+
+	placements, err := txApp.FindAllRecords(
+		advertisementsPlacementsCollectionName,
+		dbx.HashExp{"advertisement_id": config.Id},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	copies := make(map[string]*core.Record, len(placements))
+	placementErrs := validation.Errors{}
+	for _, placement := range placements {
+		placementCopy := copyRecord(placement)
+		placementCopy.Set("advertisement_id", clone.Id)
+
+		errs, err := recordValidationErrors(txApp, placementCopy)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(errs) > 0 {
+			placementErrs[placement.Id] = errs
+			continue
+		}
+
+		if err := txApp.Save(placementCopy); err != nil {
+			return nil, nil, err
+		}
+
+		copies[placement.Id] = placementCopy
+	}
+
+	if len(placementErrs) > 0 {
+		return nil, nil, validation.Errors{"placements": placementErrs}
+	}
+
+	return clone, copies, nil
+}
+
+// handleCloneAdvertisementConfig copies an advertisement config viewable by
+// the request auth record and all of its placements, e.g. to set up a new
+// game or experiment from an existing config.
+//
+// The name, experiment_id, game_id (record ids) and ad unit fields of the
+// copy can be overridden and the request auth record has to be an editor of
+// all of the copy games. The response holds the id of the new config and
+// the ids of the new placements indexed by the id of their source.
+func handleCloneAdvertisementConfig(e *core.RequestEvent) error {
+	var body struct {
+		Name                 *string  `json:"name"`
+		ExperimentID         *string  `json:"experiment_id"`
+		GameID               []string `json:"game_id"`
+		BannerAdUnitID       *string  `json:"banner_ad_unit_id"`
+		InterstitialAdUnitID *string  `json:"interstitial_ad_unit_id"`
+		RewardedAdUnitID     *string  `json:"rewarded_ad_unit_id"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	config, err := e.App.FindRecordById(advertisementConfigsCollectionName, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("config not found", nil)
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+	canView, err := e.App.CanAccessRecord(config, requestInfo, config.Collection().ViewRule)
+	if !canView {
+		return e.NotFoundError("config not found", err)
+	}
+
+	overrides := map[string]any{}
+	for field, value := range map[string]*string{
+		"name":                    body.Name,
+		"experiment_id":           body.ExperimentID,
+		"banner_ad_unit_id":       body.BannerAdUnitID,
+		"interstitial_ad_unit_id": body.InterstitialAdUnitID,
+		"rewarded_ad_unit_id":     body.RewardedAdUnitID,
+	} {
+		if value != nil {
+			overrides[field] = *value
+		}
+	}
+
+	gameIDs := configGameIDs(config)
+	if body.GameID != nil {
+		for _, id := range body.GameID {
+			if _, err := e.App.FindRecordById(gamesCollectionName, id); err != nil {
+				return e.BadRequestError(fmt.Sprintf("game %s not found", id), nil)
+			}
+		}
+		gameIDs = body.GameID
+		overrides["game_id"] = body.GameID
+	}
+
+	if err := requireGameRole(e, roleEditor, gameIDs...); err != nil {
+		return err
+	}
+
+	var clone *core.Record
+	var placements map[string]*core.Record
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		var err error
+		clone, placements, err = cloneAdvertisementConfig(txApp, config, overrides, e.Auth)
+		if err != nil {
+			return err
+		}
+
+		if err := writeAuditEntry(txApp, e, auditActionCreate, nil, clone, gameIDs); err != nil {
+			return err
+		}
+		for _, placement := range placements {
+			if err := writeAuditEntry(txApp, e, auditActionCreate, nil, placement, gameIDs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		var errs validation.Errors
+		if errors.As(err, &errs) {
+			return e.BadRequestError("failed to validate the cloned config", errs)
+		}
+		return e.BadRequestError("failed to clone the config", err)
+	}
+
+	placementIDs := make(map[string]string, len(placements))
+	for sourceID, placement := range placements {
+		placementIDs[sourceID] = placement.Id
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"id":         clone.Id,
+		"placements": placementIDs,
+	})
+}
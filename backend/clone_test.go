@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCloneGameRecordID = "clonegame000001"
+
+// seedCloneGame creates the game the seeded config is cloned to.
+func seedCloneGame(t testing.TB, app core.App) {
+	createTestRecord(t, app, gamesCollectionName, map[string]any{
+		"id":      testCloneGameRecordID,
+		"game_id": "studio.sun.puzzle",
+	})
+}
+
+// findClonedConfig returns the config created by a clone request and its
+// placements.
+func findClonedConfig(t testing.TB, app core.App, res *http.Response) (*core.Record, []*core.Record, map[string]string) {
+	var body struct {
+		ID         string            `json:"id"`
+		Placements map[string]string `json:"placements"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+
+	clone, err := app.FindRecordById(advertisementConfigsCollectionName, body.ID)
+	require.NoError(t, err)
+
+	placements, err := app.FindAllRecords(advertisementsPlacementsCollectionName, dbx.HashExp{"advertisement_id": clone.Id})
+	require.NoError(t, err)
+
+	return clone, placements, body.Placements
+}
+
+func TestCloneAdvertisementConfig(t *testing.T) {
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedClientConfig(t, app)
+		seedCloneGame(t, app)
+	}
+	url := "/api/advertisement_configs/" + testConfigRecordID + "/clone"
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"experiment_id":"variant"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:            "non-member",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"experiment_id":"variant"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{"Config not found."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "viewer",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"experiment_id":"variant"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The editor role is required in the game " + testGameRecordID + "."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				seedGameMember(t, app, roleViewer)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "editor of the source game only",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"game_id":["` + testCloneGameRecordID + `"]}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The editor role is required in the game " + testCloneGameRecordID + "."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				seedGameMember(t, app, roleEditor)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "unknown game",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"game_id":["unknown"]}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"Game unknown not found."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "same name and experiment in the same game",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"code":"validation_not_unique"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				configs, err := app.FindAllRecords(advertisementConfigsCollectionName)
+				require.NoError(t, err)
+				assert.Len(t, configs, 1, "Nothing should be created")
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "editor clones to another experiment",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"experiment_id":"variant"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"placements":{`, `"` + testPlacementRecordID + `":`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				seedGameMember(t, app, roleEditor)
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				clone, placements, placementIDs := findClonedConfig(t, app, res)
+				assert.NotEqual(t, testConfigRecordID, clone.Id)
+				assert.Equal(t, "default", clone.GetString("name"))
+				assert.Equal(t, "variant", clone.GetString("experiment_id"))
+				assert.Equal(t, []string{testGameRecordID}, configGameIDs(clone))
+				assert.Equal(t, 60, clone.GetInt("banner_refresh_rate"))
+				assert.Equal(t, clone.Id, clone.GetString("lineage_id"), "The clone should start a new lineage")
+				assert.Equal(t, 1, clone.GetInt("version"))
+				assert.True(t, clone.GetBool("is_latest"))
+				assert.Equal(t, statusDraft, clone.GetString("status"))
+
+				user, err := app.FindAuthRecordByEmail(usersCollectionName, userEmail)
+				require.NoError(t, err)
+				assert.Equal(t, user.Id, clone.GetString("author"))
+
+				require.Len(t, placements, 2)
+				assert.Len(t, placementIDs, 2)
+
+				appReady, err := app.FindRecordById(advertisementsPlacementsCollectionName, placementIDs[testPlacementRecordID])
+				require.NoError(t, err)
+				assert.Equal(t, clone.Id, appReady.GetString("advertisement_id"))
+				assert.Equal(t, 3, appReady.GetInt("min_level"))
+
+				source, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+				require.NoError(t, err)
+				assert.True(t, source.GetBool("is_latest"), "The source config should be untouched")
+				assert.Equal(t, statusPublished, source.GetString("status"))
+
+				assert.Len(t, findAuditEntries(t, app, advertisementConfigsCollectionName), 1)
+				assert.Len(t, findAuditEntries(t, app, advertisementsPlacementsCollectionName), 2)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "clone to another game with other ad units",
+			Method: http.MethodPost,
+			URL:    url,
+			Body: strings.NewReader(`{"game_id":["` + testCloneGameRecordID + `"],` +
+				`"banner_ad_unit_id":"puzzle-banner","rewarded_ad_unit_id":"puzzle-rewarded"}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"id":"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				clone, placements, _ := findClonedConfig(t, app, res)
+				assert.Equal(t, "default", clone.GetString("name"), "The name should be kept in another game")
+				assert.Equal(t, "control", clone.GetString("experiment_id"))
+				assert.Equal(t, []string{testCloneGameRecordID}, configGameIDs(clone))
+				assert.Equal(t, "puzzle-banner", clone.GetString("banner_ad_unit_id"))
+				assert.Equal(t, "interstitial-unit", clone.GetString("interstitial_ad_unit_id"))
+				assert.Equal(t, "puzzle-rewarded", clone.GetString("rewarded_ad_unit_id"))
+				assert.Len(t, placements, 2)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "placement unavailable to the other game",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"name":"puzzle","game_id":["` + testCloneGameRecordID + `"]}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"placements":{`, `"code":"validation_placement_scope"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				createTestRecord(t, app, placementsCollectionName, map[string]any{
+					"id":      testCatalogPlacementID,
+					"name":    "Screen/Shop/Open",
+					"game_id": testGameRecordID,
+				})
+				createTestRecord(t, app, advertisementsPlacementsCollectionName, map[string]any{
+					"advertisement_id": testConfigRecordID,
+					"placement_id":     testCatalogPlacementID,
+					"ad_format":        2,
+				})
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				configs, err := app.FindAllRecords(advertisementConfigsCollectionName)
+				require.NoError(t, err)
+				assert.Len(t, configs, 1, "The clone should be rolled back")
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testGameRecordID     = "testgame0000001"
+	testTemplateRecordID = "testtemplate001"
+	testConfigRecordID   = "testconfig00001"
+)
+
+// authenticateAs makes every scenario request authenticated as the given auth record.
+func authenticateAs(t testing.TB, e *core.ServeEvent, authRecord *core.Record) {
+	token, err := authRecord.NewAuthToken()
+	require.NoError(t, err, "Failed to generate auth token")
+
+	e.Router.Bind(&hook.Handler[*core.RequestEvent]{
+		Func: func(re *core.RequestEvent) error {
+			re.Request.Header.Set("Authorization", token)
+			return re.Next()
+		},
+		Priority: -99999,
+	})
+}
+
+// authenticateAsAdmin makes every scenario request authenticated as the seeded superuser.
+func authenticateAsAdmin(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+	admin, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, adminEmail)
+	require.NoError(t, err, "Failed to find admin user")
+
+	authenticateAs(t, e, admin)
+}
+
+// seedConfigurationTemplate creates a game with a single configuration template.
+func seedConfigurationTemplate(t testing.TB, app core.App) {
+	createTestRecord(t, app, gamesCollectionName, map[string]any{
+		"id":      testGameRecordID,
+		"game_id": testGameID,
+	})
+	createTestRecord(t, app, configurationTemplatesCollectionName, map[string]any{
+		"id":      testTemplateRecordID,
+		"name":    "AD_SETTINGS",
+		"game_id": testGameRecordID,
+	})
+}
+
+func TestConfigurationHooks(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "invalid template name",
+			Method: http.MethodPost,
+			URL:    "/api/collections/configuration_templates/records",
+			Body:   strings.NewReader(`{"name":"ad settings","game_id":"` + testGameRecordID + `"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"must contain only uppercase letters and underscores"},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigurationTemplate(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "invalid template schema",
+			Method: http.MethodPost,
+			URL:    "/api/collections/configuration_templates/records",
+			Body:   strings.NewReader(`{"name":"AD_SETTINGS","game_id":"` + testGameRecordID + `","schema":{"type":"object","requried":["banner"]}}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				"Configuration template schema is invalid at /requried",
+				`"schema":{"/requried":{"code":"validation_json_schema"`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigurationTemplate(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "configuration data not matching the template schema",
+			Method: http.MethodPost,
+			URL:    "/api/collections/configurations/records",
+			Body: strings.NewReader(`{
+				"name":"first",
+				"game_id":"` + testGameRecordID + `",
+				"template_id":"` + testTemplateRecordID + `",
+				"data":{"banner":"yes","bannner":true}
+			}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				"Configuration data doesn't match the template schema at /banner, /bannner",
+				`"data":{"data":{"/banner":{"code":"validation_json_schema","message":"Must be of type boolean."}`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigurationTemplate(t, app)
+				template, err := app.FindRecordById(configurationTemplatesCollectionName, testTemplateRecordID)
+				require.NoError(t, err)
+				template.Set("schema", map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties":           map[string]any{"banner": map[string]any{"type": "boolean"}},
+				})
+				require.NoError(t, app.Save(template))
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "new configuration becomes the latest one",
+			Method: http.MethodPost,
+			URL:    "/api/collections/configurations/records",
+			Body: strings.NewReader(`{
+				"name":"second",
+				"game_id":"` + testGameRecordID + `",
+				"template_id":"` + testTemplateRecordID + `",
+				"data":{"banner":true},
+				"is_latest":false
+			}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"is_latest":true`, `"name":"second"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigurationTemplate(t, app)
+				createTestRecord(t, app, configurationsCollectionName, map[string]any{
+					"id":          testConfigRecordID,
+					"name":        "first",
+					"game_id":     testGameRecordID,
+					"template_id": testTemplateRecordID,
+					"data":        map[string]any{"banner": false},
+					"is_latest":   true,
+				})
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				first, err := app.FindRecordById(configurationsCollectionName, testConfigRecordID)
+				require.NoError(t, err)
+				assert.False(t, first.GetBool("is_latest"), "Previous configuration should no longer be latest")
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "configuration moved to another template",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/configurations/records/" + testConfigRecordID,
+			Body:   strings.NewReader(`{"template_id":"testtemplate002","data":{"banner":true}}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"template_id":{"code":"validation_lineage_key"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigurationTemplate(t, app)
+				createTestRecord(t, app, configurationTemplatesCollectionName, map[string]any{
+					"id":      "testtemplate002",
+					"name":    "SHOP_SETTINGS",
+					"game_id": testGameRecordID,
+				})
+				createTestRecord(t, app, configurationsCollectionName, map[string]any{
+					"id":          testConfigRecordID,
+					"name":        "first",
+					"game_id":     testGameRecordID,
+					"template_id": testTemplateRecordID,
+					"data":        map[string]any{"banner": false},
+					"is_latest":   true,
+				})
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				total, err := app.CountRecords(configurationsCollectionName)
+				require.NoError(t, err)
+				assert.EqualValues(t, 1, total, "No version should be created")
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
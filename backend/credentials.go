@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultAccounts lists the initial accounts with the well-known passwords
+// they used to be seeded with, the emails being overridable with the
+// <env>_EMAIL environment variables.
+var defaultAccounts = []struct {
+	collection string
+	env        string
+	email      string
+	password   string
+}{
+	{core.CollectionNameSuperusers, "PB_ADMIN", "admin@sun.studio", "adminpassword123"},
+	{usersCollectionName, "PB_USER", "moderator@sun.studio", "userpassword123"},
+}
+
+// checkDefaultCredentials fails when one of the initial accounts still uses
+// its well-known default password.
+func checkDefaultCredentials(app core.App) error {
+	var insecure []string
+	for _, account := range defaultAccounts {
+		emails := []string{account.email}
+		if email := os.Getenv(account.env + "_EMAIL"); email != "" && email != account.email {
+			emails = append(emails, email)
+		}
+
+		for _, email := range emails {
+			record, err := app.FindAuthRecordByEmail(account.collection, email)
+			if err != nil {
+				continue
+			}
+
+			if record.ValidatePassword(account.password) {
+				insecure = append(insecure, email)
+			}
+		}
+	}
+
+	if len(insecure) > 0 {
+		return fmt.Errorf(
+			"the default password of %s is still in use, change it (or set PB_DEV=true for local development)",
+			strings.Join(insecure, ", "),
+		)
+	}
+
+	return nil
+}
+
+// refuseDefaultCredentials stops the server from serving outside of the dev
+// mode while the initial accounts use their default passwords.
+func refuseDefaultCredentials(se *core.ServeEvent) error {
+	if se.App.IsDev() {
+		return se.Next()
+	}
+
+	if err := checkDefaultCredentials(se.App); err != nil {
+		return err
+	}
+
+	return se.Next()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitialAccounts(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "user_password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("user-secret\n"), 0o600))
+
+	t.Setenv("PB_ADMIN_PASSWORD", "admin-secret")
+	t.Setenv("PB_USER_PASSWORD_FILE", passwordFile)
+
+	app := setupTestApp(t)
+	defer app.Cleanup()
+
+	admin, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, adminEmail)
+	require.NoError(t, err)
+	assert.True(t, admin.ValidatePassword("admin-secret"))
+
+	user, err := app.FindAuthRecordByEmail(usersCollectionName, userEmail)
+	require.NoError(t, err)
+	assert.True(t, user.ValidatePassword("user-secret"))
+
+	assert.NoError(t, checkDefaultCredentials(app))
+}
+
+func TestCheckDefaultCredentials(t *testing.T) {
+	app := setupTestApp(t)
+	defer app.Cleanup()
+
+	// the passwords are generated when none is provided
+	require.NoError(t, checkDefaultCredentials(app))
+
+	admin, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, adminEmail)
+	require.NoError(t, err)
+	admin.SetPassword("adminpassword123")
+	require.NoError(t, app.Save(admin))
+
+	err = checkDefaultCredentials(app)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), adminEmail)
+	assert.NotContains(t, err.Error(), userEmail)
+}
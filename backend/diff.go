@@ -0,0 +1,257 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Placement diff statuses.
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffChanged = "changed"
+)
+
+// recordDiff holds the content differences between two records: the fields
+// of the record only present on the right, the fields of the record only
+// present on the left and the fields with different values when both
+// records are present.
+type recordDiff struct {
+	Added   map[string]any         `json:"added"`
+	Removed map[string]any         `json:"removed"`
+	Changed map[string]auditChange `json:"changed"`
+}
+
+func (d recordDiff) isEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// placementDiff is the difference of the placements of the two configs for
+// a single catalog placement.
+type placementDiff struct {
+	// PlacementID is the name of the catalog placement.
+	PlacementID string     `json:"placement_id"`
+	Status      string     `json:"status"`
+	Fields      recordDiff `json:"fields"`
+}
+
+// configDiff is the structured difference between two advertisement
+// configs, typically two versions of the same config.
+type configDiff struct {
+	Left       string          `json:"left"`
+	Right      string          `json:"right"`
+	Fields     recordDiff      `json:"fields"`
+	Placements []placementDiff `json:"placements"`
+}
+
+// diffRecords compares the content fields of two records of the same
+// collection, ignoring the system, version and workflow fields, the hidden
+// and autodate fields and the given ones. A nil record stands for a missing
+// one, the set fields of the other record being added or removed.
+func diffRecords(left *core.Record, right *core.Record, ignored ...string) recordDiff {
+	diff := recordDiff{
+		Added:   map[string]any{},
+		Removed: map[string]any{},
+		Changed: map[string]auditChange{},
+	}
+
+	for _, field := range cmp.Or(right, left).Collection().Fields {
+		name := field.GetName()
+		if slices.Contains(systemFields, name) || slices.Contains(versionFields, name) ||
+			slices.Contains(workflowFields, name) || slices.Contains(ignored, name) ||
+			field.GetHidden() || field.Type() == core.FieldTypeAutodate {
+			continue
+		}
+
+		var before, after any
+		if left != nil {
+			before = left.Get(name)
+		}
+		if right != nil {
+			after = right.Get(name)
+		}
+
+		switch {
+		case recordValuesEqual(before, after):
+		case left == nil:
+			if !isEmptyAuditValue(after) {
+				diff.Added[name] = after
+			}
+		case right == nil:
+			if !isEmptyAuditValue(before) {
+				diff.Removed[name] = before
+			}
+		case isEmptyAuditValue(before) && isEmptyAuditValue(after):
+			// e.g. a nil and an empty list
+		default:
+			// a zero value (e.g. the banner ad format or a false flag) is a
+			// change like any other
+			diff.Changed[name] = auditChange{Before: before, After: after}
+		}
+	}
+
+	return diff
+}
+
+// diffAdvertisementConfigs compares two advertisement configs along with
+// their placements, matched by catalog placement.
+func diffAdvertisementConfigs(app core.App, left *core.Record, right *core.Record) (*configDiff, error) {
+	result := &configDiff{
+		Left:       left.Id,
+		Right:      right.Id,
+		Fields:     diffRecords(left, right),
+		Placements: []placementDiff{},
+	}
+
+	leftPlacements, err := app.FindAllRecords(advertisementsPlacementsCollectionName, dbx.HashExp{"advertisement_id": left.Id})
+	if err != nil {
+		return nil, err
+	}
+	rightPlacements, err := app.FindAllRecords(advertisementsPlacementsCollectionName, dbx.HashExp{"advertisement_id": right.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := placementNames(app, slices.Concat(leftPlacements, rightPlacements))
+	if err != nil {
+		return nil, err
+	}
+
+	// the placements are matched by catalog placement, which is unique
+	// within a config
+	pairs := map[string][2]*core.Record{}
+	for _, placement := range leftPlacements {
+		pair := pairs[placement.GetString("placement_id")]
+		pair[0] = placement
+		pairs[placement.GetString("placement_id")] = pair
+	}
+	for _, placement := range rightPlacements {
+		pair := pairs[placement.GetString("placement_id")]
+		pair[1] = placement
+		pairs[placement.GetString("placement_id")] = pair
+	}
+
+	for _, pair := range pairs {
+		diff := placementDiff{Fields: diffRecords(pair[0], pair[1], "advertisement_id", "placement_id")}
+		switch {
+		case pair[0] == nil:
+			diff.PlacementID = names[pair[1].Id]
+			diff.Status = diffAdded
+		case pair[1] == nil:
+			diff.PlacementID = names[pair[0].Id]
+			diff.Status = diffRemoved
+		case diff.Fields.isEmpty():
+			continue
+		default:
+			diff.PlacementID = names[pair[1].Id]
+			diff.Status = diffChanged
+		}
+
+		result.Placements = append(result.Placements, diff)
+	}
+
+	slices.SortFunc(result.Placements, func(a, b placementDiff) int {
+		return strings.Compare(a.PlacementID, b.PlacementID)
+	})
+
+	return result, nil
+}
+
+// String returns the human-readable form of the diff: one line per field,
+// prefixed by + (added), - (removed) or ~ (changed), the placements fields
+// being indented under their placement.
+func (d *configDiff) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", d.Left, d.Right)
+	writeRecordDiff(&b, d.Fields, "")
+
+	if len(d.Placements) > 0 {
+		b.WriteString("placements:\n")
+	}
+	for _, placement := range d.Placements {
+		prefix := map[string]string{diffAdded: "+", diffRemoved: "-", diffChanged: "~"}[placement.Status]
+		fmt.Fprintf(&b, "%s %s\n", prefix, placement.PlacementID)
+		writeRecordDiff(&b, placement.Fields, "    ")
+	}
+
+	return b.String()
+}
+
+func writeRecordDiff(b *strings.Builder, diff recordDiff, indent string) {
+	fields := slices.Concat(
+		slices.Collect(maps.Keys(diff.Added)),
+		slices.Collect(maps.Keys(diff.Removed)),
+		slices.Collect(maps.Keys(diff.Changed)),
+	)
+	slices.Sort(fields)
+
+	for _, field := range fields {
+		if value, ok := diff.Added[field]; ok {
+			fmt.Fprintf(b, "%s+ %s: %s\n", indent, field, diffValue(value))
+		} else if value, ok := diff.Removed[field]; ok {
+			fmt.Fprintf(b, "%s- %s: %s\n", indent, field, diffValue(value))
+		} else {
+			change := diff.Changed[field]
+			fmt.Fprintf(b, "%s~ %s: %s -> %s\n", indent, field, diffValue(change.Before), diffValue(change.After))
+		}
+	}
+}
+
+func diffValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// handleConfigDiff compares the advertisement configs given by the left and
+// right query parameters (record ids), which both have to be viewable by
+// the request auth record. The response holds the structured diff along
+// with its text form.
+func handleConfigDiff(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+	if query.Get("left") == "" || query.Get("right") == "" {
+		return e.BadRequestError("the left and right configs are required", nil)
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	configs := make([]*core.Record, 0, 2)
+	for _, param := range []string{"left", "right"} {
+		config, err := e.App.FindRecordById(advertisementConfigsCollectionName, query.Get(param))
+		if err != nil {
+			return e.NotFoundError(fmt.Sprintf("%s config not found", param), nil)
+		}
+
+		canView, err := e.App.CanAccessRecord(config, requestInfo, config.Collection().ViewRule)
+		if !canView {
+			return e.NotFoundError(fmt.Sprintf("%s config not found", param), err)
+		}
+
+		configs = append(configs, config)
+	}
+
+	diff, err := diffAdvertisementConfigs(e.App, configs[0], configs[1])
+	if err != nil {
+		return e.InternalServerError("failed to compare the configs", err)
+	}
+
+	return e.JSON(http.StatusOK, struct {
+		*configDiff
+		Text string `json:"text"`
+	}{diff, diff.String()})
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedChangedConfigVersion saves a second version of the seeded config with
+// a changed refresh rate, a removed rewarded ad unit, an added banner
+// setting, a changed and a removed placement and an added placement.
+func seedChangedConfigVersion(t testing.TB, app core.App) {
+	seedSecondConfigVersion(t, app)
+
+	config, err := app.FindRecordById(advertisementConfigsCollectionName, testSecondConfigRecordID)
+	require.NoError(t, err)
+	config.Load(map[string]any{
+		"banner_ad_unit_id":            "banner-unit",
+		"interstitial_ad_unit_id":      "interstitial-unit",
+		"banner_position":              1,
+		"banner_refresh_rate":          90,
+		"destroy_banner_on_low_memory": true,
+	})
+	require.NoError(t, app.Save(config))
+
+	createTestRecord(t, app, advertisementsPlacementsCollectionName, map[string]any{
+		"advertisement_id": testSecondConfigRecordID,
+		"placement_id":     findTestPlacementID(t, app, "AppReady"),
+		"ad_format":        1,
+		"min_level":        5,
+	})
+	createTestRecord(t, app, advertisementsPlacementsCollectionName, map[string]any{
+		"advertisement_id": testSecondConfigRecordID,
+		"placement_id":     findTestPlacementID(t, app, "Button/Undo/Click"),
+		"ad_format":        2,
+	})
+}
+
+func TestDiffAdvertisementConfigs(t *testing.T) {
+	app := setupTestApp(t)
+	defer app.Cleanup()
+
+	seedChangedConfigVersion(t, app)
+
+	left, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+	require.NoError(t, err)
+	right, err := app.FindRecordById(advertisementConfigsCollectionName, testSecondConfigRecordID)
+	require.NoError(t, err)
+
+	diff, err := diffAdvertisementConfigs(app, left, right)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]auditChange{
+		"banner_refresh_rate":          {Before: 60.0, After: 90.0},
+		"destroy_banner_on_low_memory": {Before: false, After: true},
+		"rewarded_ad_unit_id":          {Before: "rewarded-unit", After: ""},
+	}, diff.Fields.Changed)
+	assert.Empty(t, diff.Fields.Added, "Only the fields of a missing record are added")
+	assert.Empty(t, diff.Fields.Removed, "Only the fields of a missing record are removed")
+
+	require.Len(t, diff.Placements, 3)
+	assert.Equal(t, "AppReady", diff.Placements[0].PlacementID)
+	assert.Equal(t, diffChanged, diff.Placements[0].Status)
+	assert.Equal(t, map[string]auditChange{"min_level": {Before: 3.0, After: 5.0}}, diff.Placements[0].Fields.Changed)
+	assert.Equal(t, "Button/Hint/Click", diff.Placements[1].PlacementID)
+	assert.Equal(t, diffRemoved, diff.Placements[1].Status)
+	assert.Equal(t, 2.0, diff.Placements[1].Fields.Removed["retry"])
+	assert.Equal(t, "Button/Undo/Click", diff.Placements[2].PlacementID)
+	assert.Equal(t, diffAdded, diff.Placements[2].Status)
+
+	assert.Contains(t, diff.String(), "~ banner_refresh_rate: 60 -> 90\n~ destroy_banner_on_low_memory: false -> true\n~ rewarded_ad_unit_id: \"rewarded-unit\" -> \"\"\n")
+	assert.Contains(t, diff.String(), "placements:\n~ AppReady\n    ~ min_level: 3 -> 5\n- Button/Hint/Click\n")
+
+	t.Run("zero values", func(t *testing.T) {
+		placement, err := app.FindRecordById(advertisementsPlacementsCollectionName, testPlacementRecordID)
+		require.NoError(t, err)
+		placement.Set("show_loading", true)
+		zero := placement.Fresh()
+		zero.Set("ad_format", 0)
+		zero.Set("show_loading", false)
+
+		diff := diffRecords(placement, zero)
+		assert.Equal(t, map[string]auditChange{
+			"ad_format":    {Before: 1.0, After: 0.0},
+			"show_loading": {Before: true, After: false},
+		}, diff.Changed)
+		assert.Empty(t, diff.Added)
+		assert.Empty(t, diff.Removed)
+	})
+
+	same, err := diffAdvertisementConfigs(app, left, left)
+	require.NoError(t, err)
+	assert.True(t, same.Fields.isEmpty())
+	assert.Empty(t, same.Placements)
+}
+
+func TestConfigDiffEndpoint(t *testing.T) {
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedChangedConfigVersion(t, app)
+	}
+	url := "/api/configs/diff?left=" + testConfigRecordID + "&right=" + testSecondConfigRecordID
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodGet,
+			URL:             url,
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:            "missing right config",
+			Method:          http.MethodGet,
+			URL:             "/api/configs/diff?left=" + testConfigRecordID,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"The left and right configs are required."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "unknown config",
+			Method:          http.MethodGet,
+			URL:             "/api/configs/diff?left=" + testConfigRecordID + "&right=unknown",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{"Right config not found."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "non-member",
+			Method:          http.MethodGet,
+			URL:             url,
+			ExpectedStatus:  404,
+			ExpectedContent: []string{"Left config not found."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "viewer",
+			Method:         http.MethodGet,
+			URL:            url,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"left":"` + testConfigRecordID + `"`,
+				`"changed":{"banner_refresh_rate":{"before":60,"after":90},"destroy_banner_on_low_memory":{"before":false,"after":true}`,
+				`"placement_id":"Button/Undo/Click","status":"added"`,
+				`"text":"--- ` + testConfigRecordID,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				seedGameMember(t, app, roleViewer)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
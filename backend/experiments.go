@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// playerIDParam is the client config query parameter carrying the hashed
+// device or user id used to assign the player to an experiment variant.
+const playerIDParam = "player_id"
+
+// findRunningExperiment returns the running experiment of the game with the
+// given key, or nil when there is none.
+func findRunningExperiment(app core.App, game *core.Record, key string) (*core.Record, error) {
+	experiment, err := app.FindFirstRecordByFilter(
+		experimentsCollectionName,
+		"game_id = {:game} && key = {:key} && running = true",
+		dbx.Params{"game": game.Id, "key": key},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return experiment, nil
+}
+
+// assignVariant deterministically picks one of the experiment variants for
+// the player, proportionally to the variant weights.
+//
+// The variants are ordered by name so that the assignment doesn't depend
+// on the order they were loaded in. It returns nil when no variant has a
+// positive weight.
+func assignVariant(experimentKey string, playerID string, variants []*core.Record) *core.Record {
+	variants = slices.Clone(variants)
+	slices.SortFunc(variants, func(a, b *core.Record) int {
+		return strings.Compare(a.GetString("name"), b.GetString("name"))
+	})
+
+	total := 0
+	for _, variant := range variants {
+		total += max(variant.GetInt("weight"), 0)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	bucket := experimentBucket(experimentKey, playerID, total)
+	for _, variant := range variants {
+		bucket -= max(variant.GetInt("weight"), 0)
+		if bucket < 0 {
+			return variant
+		}
+	}
+
+	return nil
+}
+
+// experimentBucket hashes the player id (salted with the experiment key, so
+// that a player doesn't land in the same bucket of every experiment) into
+// the [0, total) range.
+func experimentBucket(experimentKey string, playerID string, total int) int {
+	sum := sha256.Sum256([]byte(experimentKey + ":" + playerID))
+
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVariantConfigRecordID = "testvariant0001"
+
+// seedExperiment seeds the client config with a second published variant of
+// the "control" experiment and the experiment itself.
+func seedExperiment(t testing.TB, app core.App, running bool, defaultWeight int, aggressiveWeight int) {
+	config := seedClientConfig(t, app)
+	config.Set("weight", defaultWeight)
+	require.NoError(t, app.Save(config))
+
+	createTestRecord(t, app, advertisementConfigsCollectionName, map[string]any{
+		"id":                  testVariantConfigRecordID,
+		"lineage_id":          testVariantConfigRecordID,
+		"version":             1,
+		"is_latest":           true,
+		"status":              statusPublished,
+		"name":                "aggressive",
+		"experiment_id":       "control",
+		"game_id":             []string{testGameRecordID},
+		"banner_refresh_rate": 30,
+		"weight":              aggressiveWeight,
+	})
+
+	createTestRecord(t, app, experimentsCollectionName, map[string]any{
+		"key":     "control",
+		"game_id": testGameRecordID,
+		"running": running,
+	})
+}
+
+func TestAssignVariant(t *testing.T) {
+	app, err := tests.NewTestApp()
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection(advertisementConfigsCollectionName)
+	newVariant := func(name string, weight int) *core.Record {
+		record := core.NewRecord(collection)
+		record.Set("name", name)
+		record.Set("weight", weight)
+		return record
+	}
+
+	control := newVariant("control", 30)
+	aggressive := newVariant("aggressive", 70)
+	disabled := newVariant("disabled", 0)
+
+	t.Run("same player gets the same variant", func(t *testing.T) {
+		first := assignVariant("ads", "player-1", []*core.Record{control, aggressive, disabled})
+		second := assignVariant("ads", "player-1", []*core.Record{disabled, aggressive, control})
+		require.NotNil(t, first)
+		assert.Same(t, first, second)
+	})
+
+	t.Run("variants follow the weights", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := range 10000 {
+			variant := assignVariant("ads", fmt.Sprintf("player-%d", i), []*core.Record{control, aggressive, disabled})
+			counts[variant.GetString("name")]++
+		}
+
+		assert.Zero(t, counts["disabled"])
+		assert.InDelta(t, 3000, counts["control"], 300)
+		assert.InDelta(t, 7000, counts["aggressive"], 300)
+	})
+
+	t.Run("no variant without weights", func(t *testing.T) {
+		assert.Nil(t, assignVariant("ads", "player-1", []*core.Record{disabled}))
+	})
+}
+
+func TestClientConfigExperiments(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:           "player is assigned to a variant",
+			Method:         http.MethodGet,
+			URL:            "/api/client-config/" + testGameID + "?player_id=5f4dcc3b5aa765d61d8327deb882cf99",
+			Headers:        map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"config_id":"` + testVariantConfigRecordID + `"`,
+				`"variant":"aggressive"`,
+				`"refresh_rate":30`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedExperiment(t, app, true, 0, 100)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:               "without player id",
+			Method:             http.MethodGet,
+			URL:                "/api/client-config/" + testGameID,
+			Headers:            map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:     200,
+			ExpectedContent:    []string{`"experiment_id":"control"`},
+			NotExpectedContent: []string{`"variant"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedExperiment(t, app, true, 0, 100)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:               "experiment not running",
+			Method:             http.MethodGet,
+			URL:                "/api/client-config/" + testGameID + "?player_id=5f4dcc3b5aa765d61d8327deb882cf99",
+			Headers:            map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:     200,
+			ExpectedContent:    []string{`"experiment_id":"control"`},
+			NotExpectedContent: []string{`"variant"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedExperiment(t, app, false, 0, 100)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// exportResult is the outcome of the export of a single game.
+type exportResult struct {
+	GameID string
+	// Path is the written snapshot, empty when the game has no served config.
+	Path string
+	// Removed is the stale snapshot of a game which no longer serves a config.
+	Removed string
+}
+
+// newExportCommand returns the command writing the client config snapshots
+// of the games (see exportClientConfigs).
+func newExportCommand(app core.App) *cobra.Command {
+	var dir string
+	var games []string
+
+	command := &cobra.Command{
+		Use:          "export",
+		Short:        "Writes the resolved client config of each game to a JSON file",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			results, err := exportClientConfigs(app, dir, games, time.Now())
+			if err != nil {
+				return err
+			}
+
+			printExportResults(command.OutOrStdout(), results)
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&dir, "out", "o", "export", "the directory the snapshots are written to")
+	command.Flags().StringSliceVarP(&games, "game", "g", nil, "export only the given games (game_id or store identifier, repeatable)")
+
+	return command
+}
+
+func printExportResults(w io.Writer, results []exportResult) {
+	for _, result := range results {
+		if result.Removed != "" {
+			fmt.Fprintf(w, "%s: removed %s, no advertisement config is served\n", result.GameID, result.Removed)
+			continue
+		}
+		if result.Path == "" {
+			fmt.Fprintf(w, "%s: skipped, no advertisement config is served\n", result.GameID)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s\n", result.GameID, result.Path)
+	}
+}
+
+// exportClientConfigs writes the client config served at the given time to
+// a default client (no targeting attributes nor player id) of each game, or
+// of the given games only, to <dir>/<game_id>.json. The snapshot of a game
+// which no longer serves a config is removed.
+//
+// The snapshots are meant to be committed and diffed so their content is
+// deterministic: the fields keep the clientConfig order and the placements
+// are sorted by name.
+func exportClientConfigs(app core.App, dir string, gameIDs []string, now time.Time) ([]exportResult, error) {
+	games, err := findExportedGames(app, gameIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	results := make([]exportResult, 0, len(games))
+	for _, game := range games {
+		result := exportResult{GameID: game.GetString("game_id")}
+
+		path := filepath.Join(dir, result.GameID+".json")
+
+		config, err := resolveClientConfig(app, game, clientContext{Now: now})
+		if errors.Is(err, errNoClientConfig) {
+			err := os.Remove(path)
+			if err == nil {
+				result.Removed = path
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+
+			results = append(results, result)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the client config of %s: %w", result.GameID, err)
+		}
+
+		data, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		result.Path = path
+		if err := os.WriteFile(result.Path, append(data, '\n'), 0o644); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// findExportedGames returns the given games (all of them when none is
+// given), sorted by game_id.
+func findExportedGames(app core.App, gameIDs []string) ([]*core.Record, error) {
+	var games []*core.Record
+	if len(gameIDs) == 0 {
+		all, err := app.FindAllRecords(gamesCollectionName)
+		if err != nil {
+			return nil, err
+		}
+		games = all
+	}
+
+	for _, id := range gameIDs {
+		game, err := findGameByBundleID(app, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("game %q not found", id)
+			}
+			return nil, err
+		}
+
+		if !slices.ContainsFunc(games, func(other *core.Record) bool { return other.Id == game.Id }) {
+			games = append(games, game)
+		}
+	}
+
+	slices.SortFunc(games, func(a, b *core.Record) int {
+		return strings.Compare(a.GetString("game_id"), b.GetString("game_id"))
+	})
+
+	return games, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportClientConfigs(t *testing.T) {
+	app := setupTestApp(t)
+	defer app.Cleanup()
+
+	seedClientConfig(t, app)
+	createTestRecord(t, app, gamesCollectionName, map[string]any{
+		"game_id": "studio.sun.puzzle",
+	})
+
+	now := time.Now()
+
+	t.Run("all games", func(t *testing.T) {
+		dir := t.TempDir()
+
+		results, err := exportClientConfigs(app, dir, nil, now)
+		require.NoError(t, err)
+		assert.Equal(t, []exportResult{
+			{GameID: "studio.sun.puzzle"},
+			{GameID: testGameID, Path: filepath.Join(dir, testGameID+".json")},
+		}, results, "Games without a served config should be skipped")
+
+		data, err := os.ReadFile(filepath.Join(dir, testGameID+".json"))
+		require.NoError(t, err)
+
+		var config clientConfig
+		require.NoError(t, json.Unmarshal(data, &config))
+		assert.Equal(t, testConfigRecordID, config.ConfigID)
+		assert.Len(t, config.Placements, 2)
+
+		// exporting the same configs again gives the same snapshot
+		_, err = exportClientConfigs(app, dir, nil, now)
+		require.NoError(t, err)
+		again, err := os.ReadFile(filepath.Join(dir, testGameID+".json"))
+		require.NoError(t, err)
+		assert.Equal(t, string(data), string(again))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("stale snapshots", func(t *testing.T) {
+		dir := t.TempDir()
+		stale := filepath.Join(dir, "studio.sun.puzzle.json")
+		require.NoError(t, os.WriteFile(stale, []byte("{}\n"), 0o644))
+
+		results, err := exportClientConfigs(app, dir, nil, now)
+		require.NoError(t, err)
+		assert.Equal(t, []exportResult{
+			{GameID: "studio.sun.puzzle", Removed: stale},
+			{GameID: testGameID, Path: filepath.Join(dir, testGameID+".json")},
+		}, results)
+
+		assert.NoFileExists(t, stale, "The snapshot of a game without a served config should be removed")
+
+		var out strings.Builder
+		printExportResults(&out, results)
+		assert.Contains(t, out.String(), "studio.sun.puzzle: removed "+stale+", no advertisement config is served")
+	})
+
+	t.Run("game filter", func(t *testing.T) {
+		dir := t.TempDir()
+
+		results, err := exportClientConfigs(app, dir, []string{"Studio.Sun.RPG"}, now)
+		require.NoError(t, err)
+		assert.Equal(t, []exportResult{
+			{GameID: testGameID, Path: filepath.Join(dir, testGameID+".json")},
+		}, results)
+
+		_, err = exportClientConfigs(app, dir, []string{"studio.sun.unknown"}, now)
+		assert.ErrorContains(t, err, "not found")
+	})
+}
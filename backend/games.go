@@ -0,0 +1,100 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Game lifecycle statuses.
+const (
+	gameStatusDevelopment = "development"
+	gameStatusLive        = "live"
+	gameStatusSunset      = "sunset"
+)
+
+// gameBundleIDFields lists the games fields holding bundle identifiers: the
+// platform independent game_id and the optional per-platform store ids.
+var gameBundleIDFields = []string{"game_id", "ios_bundle_id", "android_package_name"}
+
+var (
+	// iosBundleIDPattern matches the reverse-DNS iOS bundle identifiers
+	// (letters, digits and hyphens) in their normalized lowercase form.
+	iosBundleIDPattern = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)+$`)
+
+	// androidPackagePattern matches the Android application ids, every
+	// segment starting with a letter, in their normalized lowercase form.
+	androidPackagePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)+$`)
+)
+
+// normalizeBundleID trims the whitespace around a bundle identifier and
+// lowercases it, the stores treating the identifiers case-insensitively.
+func normalizeBundleID(bundleID string) string {
+	return strings.ToLower(strings.TrimSpace(bundleID))
+}
+
+// normalizeGameBundleIDs normalizes the bundle identifiers of a game before
+// they are validated and saved.
+func normalizeGameBundleIDs(e *core.RecordRequestEvent) error {
+	for _, field := range gameBundleIDFields {
+		e.Record.Set(field, normalizeBundleID(e.Record.GetString(field)))
+	}
+
+	return e.Next()
+}
+
+// startGameDevelopment makes the new games without a status start in
+// development.
+func startGameDevelopment(e *core.RecordRequestEvent) error {
+	if e.Record.GetString("status") == "" {
+		e.Record.Set("status", gameStatusDevelopment)
+	}
+
+	return e.Next()
+}
+
+// validateGameBundleIDs validates the format of the game bundle identifiers.
+// The platform independent game_id has to be valid on at least one platform.
+func validateGameBundleIDs(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	gameID := record.GetString("game_id")
+	if gameID != "" && !iosBundleIDPattern.MatchString(gameID) && !androidPackagePattern.MatchString(gameID) {
+		errs["game_id"] = validation.NewError("validation_invalid_bundle_id", "must be a reverse-DNS bundle identifier like studio.sun.rpg")
+	}
+
+	if bundleID := record.GetString("ios_bundle_id"); bundleID != "" && !iosBundleIDPattern.MatchString(bundleID) {
+		errs["ios_bundle_id"] = validation.NewError("validation_invalid_bundle_id", "must be a valid iOS bundle identifier")
+	}
+
+	if packageName := record.GetString("android_package_name"); packageName != "" && !androidPackagePattern.MatchString(packageName) {
+		errs["android_package_name"] = validation.NewError("validation_invalid_bundle_id", "must be a valid Android package name")
+	}
+
+	return errs, nil
+}
+
+// findGameByBundleID finds the game by its game_id or, when there is no such
+// game, by one of its per-platform store identifiers.
+func findGameByBundleID(app core.App, bundleID string) (*core.Record, error) {
+	bundleID = normalizeBundleID(bundleID)
+	if bundleID == "" {
+		return nil, sql.ErrNoRows
+	}
+
+	game, err := app.FindFirstRecordByData(gamesCollectionName, "game_id", bundleID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return game, err
+	}
+
+	return app.FindFirstRecordByFilter(
+		gamesCollectionName,
+		"ios_bundle_id = {:id} || android_package_name = {:id}",
+		dbx.Params{"id": bundleID},
+	)
+}
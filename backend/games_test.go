@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleIDPatterns(t *testing.T) {
+	testCases := []struct {
+		bundleID string
+		ios      bool
+		android  bool
+	}{
+		{"studio.sun.rpg", true, true},
+		{"studio.sun.rpg-lite", true, false},
+		{"studio.sun.rpg_lite", false, true},
+		{"studio.sun.2048", true, false},
+		{"studio", false, false},
+		{"studio..rpg", false, false},
+		{"studio sun rpg", false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.bundleID, func(t *testing.T) {
+			assert.Equal(t, tc.ios, iosBundleIDPattern.MatchString(tc.bundleID), "iOS")
+			assert.Equal(t, tc.android, androidPackagePattern.MatchString(tc.bundleID), "Android")
+		})
+	}
+
+	assert.Equal(t, "studio.sun.rpg", normalizeBundleID(" Studio.Sun.RPG\n"))
+}
+
+func TestGameIconMimeTypes(t *testing.T) {
+	app := setupTestApp(t)
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId(gamesCollectionName)
+	require.NoError(t, err)
+
+	icon, ok := collection.Fields.GetByName("icon").(*core.FileField)
+	require.True(t, ok)
+	assert.Equal(t, []string{"image/png", "image/jpeg", "image/webp"}, icon.MimeTypes, "SVG icons could run scripts")
+}
+
+func TestGameBundleIDs(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "bundle identifiers are normalized and new games start in development",
+			Method: http.MethodPost,
+			URL:    "/api/collections/games/records",
+			Body:   strings.NewReader(`{"game_id":" Studio.Sun.Puzzle ","ios_bundle_id":"Studio.Sun.Puzzle-iOS","android_package_name":"studio.sun.puzzle_android "}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"game_id":"studio.sun.puzzle"`,
+				`"ios_bundle_id":"studio.sun.puzzle-ios"`,
+				`"android_package_name":"studio.sun.puzzle_android"`,
+				`"status":"development"`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "invalid bundle identifiers",
+			Method: http.MethodPost,
+			URL:    "/api/collections/games/records",
+			Body:   strings.NewReader(`{"game_id":"sun puzzle","ios_bundle_id":"studio.sun.puzzle_ios","android_package_name":"studio.sun.2048"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"game_id":{"code":"validation_invalid_bundle_id"`,
+				`"ios_bundle_id":{"code":"validation_invalid_bundle_id","message":"Must be a valid iOS bundle identifier."}`,
+				`"android_package_name":{"code":"validation_invalid_bundle_id","message":"Must be a valid Android package name."}`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "client config by store identifier",
+			Method:         http.MethodGet,
+			URL:            "/api/client-config/Studio.Sun.RPG-iOS",
+			Headers:        map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"game_id":"studio.sun.rpg"`,
+				`"config_id":"` + testConfigRecordID + `"`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+
+				game, err := app.FindRecordById(gamesCollectionName, testGameRecordID)
+				require.NoError(t, err)
+				game.Set("ios_bundle_id", "studio.sun.rpg-ios")
+				require.NoError(t, app.Save(game))
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,802 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// importDocument is the content of an import file. The records are plain
+// field maps, with the following differences from the collections:
+//   - the game_id of a config lists the game_id of its games;
+//   - the placements of a config are listed under its placements key, their
+//     placement_id being the name of their catalog placement.
+type importDocument struct {
+	Games   []map[string]any `json:"games"`
+	Configs []map[string]any `json:"configs"`
+}
+
+// importChange is a change of a record made (or planned) by an import.
+type importChange struct {
+	Action     string `json:"action"`
+	Collection string `json:"collection"`
+	// Key is the natural key of the record.
+	Key string `json:"key"`
+	// Fields lists the changed fields of the updates.
+	Fields []string `json:"fields,omitempty"`
+	// Diff holds the before and after values of the changed fields.
+	Diff map[string]auditChange `json:"diff,omitempty"`
+}
+
+func (c importChange) String() string {
+	result := fmt.Sprintf("%s %s %s", c.Action, c.Collection, c.Key)
+	if len(c.Fields) > 0 {
+		result += " (" + strings.Join(c.Fields, ", ") + ")"
+	}
+
+	return result
+}
+
+// errImportDryRun rolls back the import transaction of a dry run.
+var errImportDryRun = errors.New("import dry run")
+
+// newImportCommand returns the command upserting the games, advertisement
+// configs and placements of JSON or YAML files (see importRecords).
+func newImportCommand(app core.App) *cobra.Command {
+	var dryRun bool
+
+	command := &cobra.Command{
+		Use:          "import <file>...",
+		Short:        "Creates or updates the games, advertisement configs and placements of JSON or YAML files",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			documents := make([]*importDocument, 0, len(args))
+			for _, path := range args {
+				document, err := readImportFile(path)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", path, err)
+				}
+				documents = append(documents, document)
+			}
+
+			changes, err := importRecords(app, documents, dryRun)
+			if err != nil {
+				return err
+			}
+
+			out := command.OutOrStdout()
+			printImportChanges(out, changes)
+			if dryRun {
+				fmt.Fprintf(out, "%d changes planned, nothing was written (dry run)\n", len(changes))
+			} else {
+				fmt.Fprintf(out, "%d changes imported\n", len(changes))
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&dryRun, "dry-run", false, "print the planned changes without writing them")
+
+	return command
+}
+
+// printImportChanges prints the changes of an import, followed by the before
+// and after values of the updated fields.
+func printImportChanges(w io.Writer, changes []importChange) {
+	for _, change := range changes {
+		fmt.Fprintln(w, change)
+		for _, field := range change.Fields {
+			diff := change.Diff[field]
+			before, _ := json.Marshal(diff.Before)
+			after, _ := json.Marshal(diff.After)
+			fmt.Fprintf(w, "    %s: %s -> %s\n", field, before, after)
+		}
+	}
+}
+
+// readImportFile decodes a .json, .yaml or .yml import file.
+func readImportFile(path string) (*importDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+	case ".yaml", ".yml":
+		// the YAML documents are converted to JSON so that both formats are
+		// decoded (and checked) the same way
+		var value any
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("the import files must be .json, .yaml or .yml files")
+	}
+
+	var document importDocument
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// importRecords upserts the records of the documents in a single
+// transaction and returns the changes made, in order. The games are
+// imported first, so that the configs can refer to the games of any
+// document.
+//
+// The records are matched on their natural key: the game_id of the games,
+// the name, experiment_id and games of the advertisement configs (latest
+// versions, see findLatestConfig) and the catalog placement of the
+// placements of a config. Only the given fields are changed but the
+// placements listed for a config replace all of its placements, a null
+// list leaving them untouched like a missing one. Like any change of their
+// content, the imported configs are saved as new draft versions that have
+// to go through the publishing workflow.
+//
+// A dry run validates and plans the same changes but rolls them back.
+func importRecords(app core.App, documents []*importDocument, dryRun bool) ([]importChange, error) {
+	return runImport(app, &recordImporter{}, dryRun, func(importer *recordImporter) error {
+		for _, document := range documents {
+			for _, data := range document.Games {
+				if err := importer.importGame(data); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, document := range documents {
+			for _, data := range document.Configs {
+				if err := importer.importConfig(data); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// runImport runs an import in a single transaction, which is rolled back on
+// dry runs, and returns the changes made.
+func runImport(app core.App, importer *recordImporter, dryRun bool, run func(importer *recordImporter) error) ([]importChange, error) {
+	err := app.RunInTransaction(func(txApp core.App) error {
+		importer.app = txApp
+		importer.changes = nil
+		importer.seen = map[string]bool{}
+
+		if err := run(importer); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errImportDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return nil, err
+	}
+
+	return importer.changes, nil
+}
+
+type recordImporter struct {
+	app core.App
+	// event is the request running the import, nil for the commands.
+	event *core.RequestEvent
+	// publish makes the imported configs published versions instead of
+	// drafts, for content that was already approved elsewhere.
+	publish bool
+
+	changes []importChange
+	// seen holds the keys of the imported records, which can't be imported
+	// twice.
+	seen map[string]bool
+}
+
+// author returns the auth record of the import request, if any.
+func (i *recordImporter) author() *core.Record {
+	if i.event == nil {
+		return nil
+	}
+
+	return i.event.Auth
+}
+
+// markSeen makes sure the record is imported only once.
+func (i *recordImporter) markSeen(collection string, key string) error {
+	if i.seen[collection+"/"+key] {
+		return fmt.Errorf("%s %s is imported more than once", collection, key)
+	}
+	i.seen[collection+"/"+key] = true
+
+	return nil
+}
+
+// save validates and saves an imported record.
+func (i *recordImporter) save(key string, record *core.Record) error {
+	collection := record.Collection().Name
+
+	errs, err := recordValidationErrors(i.app, record)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s %s: %w", collection, key, errs)
+	}
+
+	if err := i.app.Save(record); err != nil {
+		return fmt.Errorf("%s %s: %w", collection, key, err)
+	}
+
+	return nil
+}
+
+// track records a change made by the import in the audit log, the before
+// state being nil for the created records and the after state nil for the
+// deleted ones.
+func (i *recordImporter) track(change importChange, before *core.Record, after *core.Record) error {
+	gameIDs, err := recordGameIDs(i.app, cmp.Or(after, before))
+	if err != nil {
+		return err
+	}
+	if before != nil && after != nil {
+		beforeIDs, err := recordGameIDs(i.app, before)
+		if err != nil {
+			return err
+		}
+		for _, id := range beforeIDs {
+			if !slices.Contains(gameIDs, id) {
+				gameIDs = append(gameIDs, id)
+			}
+		}
+	}
+
+	if err := writeAuditEntry(i.app, i.event, change.Action, before, after, gameIDs); err != nil {
+		return err
+	}
+
+	if len(change.Fields) > 0 {
+		diff := auditChanges(before, after)
+		change.Diff = make(map[string]auditChange, len(change.Fields))
+		for _, field := range change.Fields {
+			change.Diff[field] = diff[field]
+		}
+	}
+
+	i.changes = append(i.changes, change)
+
+	return nil
+}
+
+// saveAndTrack saves a created (before being nil) or updated record and
+// tracks its change.
+func (i *recordImporter) saveAndTrack(key string, before *core.Record, record *core.Record, fields []string) error {
+	if err := i.save(key, record); err != nil {
+		return err
+	}
+
+	change := importChange{Action: auditActionCreate, Collection: record.Collection().Name, Key: key}
+	if before != nil {
+		change.Action = auditActionUpdate
+		change.Fields = fields
+	}
+
+	return i.track(change, before, record)
+}
+
+// importGame creates or updates a game matched on its game_id.
+func (i *recordImporter) importGame(data map[string]any) error {
+	gameID, _ := data["game_id"].(string)
+	gameID = normalizeBundleID(gameID)
+	if gameID == "" {
+		return errors.New("a game_id is required for every imported game")
+	}
+	if err := i.markSeen(gamesCollectionName, gameID); err != nil {
+		return err
+	}
+
+	game, err := i.app.FindFirstRecordByData(gamesCollectionName, "game_id", gameID)
+	if errors.Is(err, sql.ErrNoRows) {
+		collection, err := i.app.FindCollectionByNameOrId(gamesCollectionName)
+		if err != nil {
+			return err
+		}
+		game = core.NewRecord(collection)
+		game.Set("status", gameStatusDevelopment)
+	} else if err != nil {
+		return err
+	}
+
+	var before *core.Record
+	if !game.IsNew() {
+		before = game.Original()
+	}
+
+	if err := setImportFields(game, data); err != nil {
+		return fmt.Errorf("%s %s: %w", gamesCollectionName, gameID, err)
+	}
+	for _, field := range gameBundleIDFields {
+		game.Set(field, normalizeBundleID(game.GetString(field)))
+	}
+
+	fields := importChangedFields(before, game)
+	if before != nil && len(fields) == 0 {
+		return nil
+	}
+
+	return i.saveAndTrack(gameID, before, game, fields)
+}
+
+// importConfig creates or updates an advertisement config matched on its
+// name, experiment_id and games, along with its placements when they are listed.
+func (i *recordImporter) importConfig(data map[string]any) error {
+	name, _ := data["name"].(string)
+	experimentID, _ := data["experiment_id"].(string)
+	if name == "" || experimentID == "" {
+		return errors.New("a name and an experiment_id are required for every imported advertisement config")
+	}
+	key := fmt.Sprintf("%s [%s]", name, experimentID)
+
+	var gameIDs []string
+	if games, ok := data["game_id"]; ok {
+		var err error
+		gameIDs, err = i.findGameIDs(games)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", advertisementConfigsCollectionName, key, err)
+		}
+	}
+
+	// the same name and experiment can be used by the configs of other games
+	seenKey := key
+	if gameIDs != nil {
+		seenKey += fmt.Sprint(" ", data["game_id"])
+	}
+	if err := i.markSeen(advertisementConfigsCollectionName, seenKey); err != nil {
+		return err
+	}
+
+	latest, err := i.findLatestConfig(name, experimentID, gameIDs)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", advertisementConfigsCollectionName, key, err)
+	}
+
+	var next *core.Record
+	if latest != nil {
+		next = copyRecord(latest)
+	} else {
+		collection, err := i.app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+		next = core.NewRecord(collection)
+	}
+
+	if err := setImportFields(next, data, "game_id", "placements"); err != nil {
+		return fmt.Errorf("%s %s: %w", advertisementConfigsCollectionName, key, err)
+	}
+	if gameIDs != nil {
+		next.Set("game_id", gameIDs)
+	}
+
+	var changed []string
+	if latest != nil {
+		changed = importChangedFields(latest, next)
+	}
+
+	placements, err := i.planPlacements(key, latest, next, data)
+	if err != nil {
+		return err
+	}
+
+	if latest != nil && len(changed) == 0 && !placements.changed() {
+		if i.publish && latest.GetString("status") != statusPublished {
+			return i.publishUnchanged(key, latest)
+		}
+		return nil
+	}
+
+	// like the record requests, every change creates a new draft version,
+	// published right away when the content was approved elsewhere
+	startDraft(next, i.author())
+	if i.publish {
+		next.Set("status", statusPublished)
+	}
+	if latest != nil {
+		next.Set("lineage_id", latest.GetString("lineage_id"))
+	} else {
+		next.Id = core.GenerateDefaultRandomId()
+		next.Set("lineage_id", next.Id)
+	}
+	if err := prepareNextVersion(i.app, next); err != nil {
+		return err
+	}
+
+	// when only the placements changed, they are reported on their own
+	if latest != nil && len(changed) == 0 {
+		err = i.save(key, next)
+	} else {
+		err = i.saveAndTrack(key, latest, next, changed)
+	}
+	if err != nil {
+		return err
+	}
+
+	copies := map[string]*core.Record{}
+	if latest != nil {
+		copies, err = copyAdvertisementPlacements(i.app, latest, next)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := placements.apply(i, next, copies); err != nil {
+		return err
+	}
+
+	if i.publish {
+		return archivePublishedVersions(i.app, i.event, next)
+	}
+
+	return nil
+}
+
+// findLatestConfig returns the latest version of the advertisement config
+// with the given name and experiment_id sharing a game with the imported one
+// or nil when there is none. Without games, the name and experiment_id must
+// match a single config.
+func (i *recordImporter) findLatestConfig(name string, experimentID string, gameIDs []string) (*core.Record, error) {
+	candidates, err := i.app.FindAllRecords(
+		advertisementConfigsCollectionName,
+		dbx.HashExp{"is_latest": true, "name": name, "experiment_id": experimentID},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if gameIDs != nil {
+		candidates = slices.DeleteFunc(candidates, func(config *core.Record) bool {
+			return !slices.ContainsFunc(configGameIDs(config), func(gameID string) bool {
+				return slices.Contains(gameIDs, gameID)
+			})
+		})
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, errors.New("several games have a config with this name and experiment_id, list the game_id of the imported one")
+	}
+}
+
+// publishUnchanged publishes the latest version of a config whose content
+// already matches the imported one.
+func (i *recordImporter) publishUnchanged(key string, latest *core.Record) error {
+	before := latest.Fresh()
+	latest.Set("status", statusPublished)
+	if err := i.app.Save(latest); err != nil {
+		return fmt.Errorf("%s %s: %w", advertisementConfigsCollectionName, key, err)
+	}
+
+	if err := archivePublishedVersions(i.app, i.event, latest); err != nil {
+		return err
+	}
+
+	change := importChange{
+		Action:     auditActionUpdate,
+		Collection: advertisementConfigsCollectionName,
+		Key:        key,
+		Fields:     []string{"status"},
+	}
+
+	return i.track(change, before, latest)
+}
+
+// findGameIDs returns the record ids of the games listed by game_id (or
+// store identifier) in an imported config.
+func (i *recordImporter) findGameIDs(value any) ([]string, error) {
+	var bundleIDs []string
+	switch value := value.(type) {
+	case string:
+		bundleIDs = []string{value}
+	case []any:
+		for _, item := range value {
+			bundleID, ok := item.(string)
+			if !ok {
+				return nil, errors.New("game_id must list the game_id of the config games")
+			}
+			bundleIDs = append(bundleIDs, bundleID)
+		}
+	default:
+		return nil, errors.New("game_id must list the game_id of the config games")
+	}
+
+	ids := make([]string, 0, len(bundleIDs))
+	for _, bundleID := range bundleIDs {
+		game, err := findGameByBundleID(i.app, bundleID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("game %q not found", bundleID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, game.Id)
+	}
+
+	return ids, nil
+}
+
+// placementImport is the planned import of a single placement of a config.
+type placementImport struct {
+	name string
+	// existing is the placement of the latest config version, nil for the
+	// new placements.
+	existing *core.Record
+	// record is the imported placement, not yet attached to its config.
+	record *core.Record
+	fields []string
+}
+
+// placementsImport is the planned import of the placements of a config.
+type placementsImport struct {
+	key     string
+	imports []placementImport
+	// deleted lists the placements of the latest config version which
+	// aren't listed anymore.
+	deleted []placementImport
+}
+
+func (p *placementsImport) changed() bool {
+	if len(p.deleted) > 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(p.imports, func(placement placementImport) bool {
+		return placement.existing == nil || len(placement.fields) > 0
+	})
+}
+
+// planPlacements matches the placements listed for a config with the
+// placements of its latest version. The placements are left untouched when
+// none are listed.
+func (i *recordImporter) planPlacements(key string, latest *core.Record, next *core.Record, data map[string]any) (*placementsImport, error) {
+	plan := &placementsImport{key: key}
+
+	// only an explicit empty list removes all of the placements, a null
+	// value being usually an empty YAML key
+	value, ok := data["placements"]
+	if !ok || value == nil {
+		return plan, nil
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s %s: placements must be a list", advertisementConfigsCollectionName, key)
+	}
+
+	collection, err := i.app.FindCollectionByNameOrId(advertisementsPlacementsCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]*core.Record{}
+	if latest != nil {
+		placements, err := i.app.FindAllRecords(collection, dbx.HashExp{"advertisement_id": latest.Id})
+		if err != nil {
+			return nil, err
+		}
+		for _, placement := range placements {
+			existing[placement.GetString("placement_id")] = placement
+		}
+	}
+
+	for _, item := range list {
+		fields, _ := item.(map[string]any)
+		name, _ := fields["placement_id"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("%s %s: a placement_id is required for every placement", advertisementConfigsCollectionName, key)
+		}
+		if err := i.markSeen(advertisementsPlacementsCollectionName, key+" "+name); err != nil {
+			return nil, err
+		}
+
+		entry, err := findCatalogPlacement(i.app, name, configGameIDs(next))
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", advertisementConfigsCollectionName, key, err)
+		}
+
+		placement := placementImport{name: name, existing: existing[entry.Id]}
+		delete(existing, entry.Id)
+
+		if placement.existing != nil {
+			placement.record = copyRecord(placement.existing)
+		} else {
+			placement.record = core.NewRecord(collection)
+			placement.record.Set("placement_id", entry.Id)
+		}
+		if err := setImportFields(placement.record, fields, "placement_id"); err != nil {
+			return nil, fmt.Errorf("%s %s %s: %w", advertisementsPlacementsCollectionName, key, name, err)
+		}
+		if placement.existing != nil {
+			placement.fields = importChangedFields(placement.existing, placement.record)
+		}
+
+		plan.imports = append(plan.imports, placement)
+	}
+
+	if len(existing) > 0 {
+		names, err := placementNames(i.app, slices.Collect(maps.Values(existing)))
+		if err != nil {
+			return nil, err
+		}
+		for _, placement := range existing {
+			plan.deleted = append(plan.deleted, placementImport{name: names[placement.Id], existing: placement})
+		}
+		slices.SortFunc(plan.deleted, func(a, b placementImport) int {
+			return strings.Compare(a.name, b.name)
+		})
+	}
+
+	return plan, nil
+}
+
+// apply saves the planned placements into the new config version, whose
+// copies of the latest version placements are indexed by source id.
+func (p *placementsImport) apply(i *recordImporter, config *core.Record, copies map[string]*core.Record) error {
+	for _, placement := range p.imports {
+		key := p.key + " " + placement.name
+
+		if placement.existing == nil {
+			placement.record.Set("advertisement_id", config.Id)
+			if err := i.saveAndTrack(key, nil, placement.record, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if len(placement.fields) == 0 {
+			continue
+		}
+
+		placementCopy := copies[placement.existing.Id]
+		before := placementCopy.Fresh()
+		for _, field := range placement.fields {
+			placementCopy.Set(field, placement.record.Get(field))
+		}
+		if err := i.saveAndTrack(key, before, placementCopy, placement.fields); err != nil {
+			return err
+		}
+	}
+
+	for _, placement := range p.deleted {
+		placementCopy := copies[placement.existing.Id]
+		if err := i.app.Delete(placementCopy); err != nil {
+			return err
+		}
+
+		change := importChange{
+			Action:     auditActionDelete,
+			Collection: advertisementsPlacementsCollectionName,
+			Key:        p.key + " " + placement.name,
+		}
+		if err := i.track(change, placementCopy, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findCatalogPlacement returns the catalog placement with the given name
+// available to the given games.
+func findCatalogPlacement(app core.App, name string, gameIDs []string) (*core.Record, error) {
+	entries, err := app.FindAllRecords(placementsCollectionName, dbx.HashExp{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		scope := entry.GetString("game_id")
+		if scope == "" || slices.Contains(gameIDs, scope) {
+			return entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("placement %q not found in the catalog", name)
+}
+
+// unimportableFieldTypes lists the field types that can't be expressed in an
+// import file.
+var unimportableFieldTypes = []string{core.FieldTypeAutodate, core.FieldTypeFile, core.FieldTypeRelation}
+
+// isImportableField reports whether a field of the collection can be set by
+// an import. The fields maintained by the server (system, version and
+// workflow fields) and the hidden, file and relation fields can't.
+func isImportableField(collection *core.Collection, field core.Field) bool {
+	name := field.GetName()
+	if slices.Contains(systemFields, name) || slices.Contains(versionFields, name) {
+		return false
+	}
+	if collection.Name == advertisementConfigsCollectionName && slices.Contains(workflowFields, name) {
+		return false
+	}
+
+	return !field.GetHidden() && !slices.Contains(unimportableFieldTypes, field.Type())
+}
+
+// setImportFields sets the imported fields of a record, except the managed
+// ones which are handled by the caller.
+func setImportFields(record *core.Record, data map[string]any, managed ...string) error {
+	for _, name := range slices.Sorted(maps.Keys(data)) {
+		if slices.Contains(managed, name) {
+			continue
+		}
+
+		field := record.Collection().Fields.GetByName(name)
+		if field == nil {
+			return fmt.Errorf("unknown field %q", name)
+		}
+		if !isImportableField(record.Collection(), field) {
+			return fmt.Errorf("field %q can't be imported", name)
+		}
+
+		record.Set(name, data[name])
+	}
+
+	return nil
+}
+
+// importFields returns the importable fields of a record.
+func importFields(record *core.Record) map[string]any {
+	fields := map[string]any{}
+	for _, field := range record.Collection().Fields {
+		if isImportableField(record.Collection(), field) {
+			fields[field.GetName()] = record.Get(field.GetName())
+		}
+	}
+
+	return fields
+}
+
+// importChangedFields returns the sorted names of the fields changed by an
+// import, none for the created records.
+func importChangedFields(before *core.Record, after *core.Record) []string {
+	if before == nil {
+		return nil
+	}
+
+	// the copies of the advertisement configs don't have the version and
+	// workflow fields of their source
+	if after.Collection().Name == advertisementConfigsCollectionName {
+		return slices.Sorted(slices.Values(changedRecordFields(before, after)))
+	}
+
+	return slices.Sorted(maps.Keys(auditChanges(before, after)))
+}
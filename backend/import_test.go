@@ -0,0 +1,283 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeImportFile writes an import file into a temporary directory.
+func writeImportFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	return path
+}
+
+func TestImportRecords(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		app := setupTestApp(t)
+		defer app.Cleanup()
+
+		document, err := readImportFile(writeImportFile(t, "games.yaml", `
+games:
+  - game_id: Studio.Sun.Puzzle
+    name: Puzzle
+    platforms: [ios, android]
+configs:
+  - name: default
+    experiment_id: control
+    game_id: [studio.sun.puzzle]
+    interstitial_ad_unit_id: interstitial-unit
+    banner_refresh_rate: 60
+    placements:
+      - placement_id: AppReady
+        ad_format: 1
+        min_level: 3
+`))
+		require.NoError(t, err)
+
+		expected := []string{
+			"create games studio.sun.puzzle",
+			"create advertisement_configs default [control]",
+			"create advertisements_placements default [control] AppReady",
+		}
+
+		changes, err := importRecords(app, []*importDocument{document}, true)
+		require.NoError(t, err)
+		assertImportChanges(t, expected, changes)
+
+		_, err = app.FindFirstRecordByData(gamesCollectionName, "game_id", "studio.sun.puzzle")
+		assert.Error(t, err, "A dry run should not write anything")
+
+		changes, err = importRecords(app, []*importDocument{document}, false)
+		require.NoError(t, err)
+		assertImportChanges(t, expected, changes)
+
+		game, err := app.FindFirstRecordByData(gamesCollectionName, "game_id", "studio.sun.puzzle")
+		require.NoError(t, err)
+		assert.Equal(t, "Puzzle", game.GetString("name"))
+		assert.Equal(t, gameStatusDevelopment, game.GetString("status"))
+
+		config, err := app.FindFirstRecordByData(advertisementConfigsCollectionName, "name", "default")
+		require.NoError(t, err)
+		assert.Equal(t, []string{game.Id}, configGameIDs(config))
+		assert.Equal(t, statusDraft, config.GetString("status"))
+		assert.Equal(t, 1, config.GetInt("version"))
+		assert.Equal(t, config.Id, config.GetString("lineage_id"))
+
+		placements, err := app.FindAllRecords(advertisementsPlacementsCollectionName, dbx.HashExp{"advertisement_id": config.Id})
+		require.NoError(t, err)
+		require.Len(t, placements, 1)
+		assert.Equal(t, 3, placements[0].GetInt("min_level"))
+
+		for _, collection := range auditedCollections {
+			assert.Len(t, findAuditEntries(t, app, collection), 1, "Every imported record should be audited")
+		}
+
+		changes, err = importRecords(app, []*importDocument{document}, false)
+		require.NoError(t, err)
+		assert.Empty(t, changes, "Importing the same records again should not change anything")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		app := setupTestApp(t)
+		defer app.Cleanup()
+
+		seedClientConfig(t, app)
+
+		document, err := readImportFile(writeImportFile(t, "configs.json", `{
+			"configs": [{
+				"name": "default",
+				"experiment_id": "control",
+				"banner_refresh_rate": 90,
+				"placements": [{"placement_id": "AppReady", "ad_format": 1, "min_level": 5}]
+			}]
+		}`))
+		require.NoError(t, err)
+
+		changes, err := importRecords(app, []*importDocument{document}, false)
+		require.NoError(t, err)
+		assertImportChanges(t, []string{
+			"update advertisement_configs default [control] (banner_refresh_rate)",
+			"update advertisements_placements default [control] AppReady (min_level)",
+			"delete advertisements_placements default [control] Button/Hint/Click",
+		}, changes)
+
+		previous, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+		require.NoError(t, err)
+		assert.Equal(t, statusPublished, previous.GetString("status"), "The served version should stay untouched")
+		assert.False(t, previous.GetBool("is_latest"))
+
+		latest, err := app.FindFirstRecordByFilter(
+			advertisementConfigsCollectionName,
+			"lineage_id = {:lineage} && is_latest = true",
+			dbx.Params{"lineage": testConfigRecordID},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, 2, latest.GetInt("version"))
+		assert.Equal(t, statusDraft, latest.GetString("status"))
+		assert.Equal(t, 90, latest.GetInt("banner_refresh_rate"))
+		assert.Equal(t, []string{testGameRecordID}, configGameIDs(latest), "The games should be kept when not listed")
+
+		placements, err := app.FindAllRecords(advertisementsPlacementsCollectionName, dbx.HashExp{"advertisement_id": latest.Id})
+		require.NoError(t, err)
+		require.Len(t, placements, 1)
+		assert.Equal(t, 5, placements[0].GetInt("min_level"))
+
+		previousPlacements, err := app.FindAllRecords(advertisementsPlacementsCollectionName, dbx.HashExp{"advertisement_id": testConfigRecordID})
+		require.NoError(t, err)
+		assert.Len(t, previousPlacements, 2)
+	})
+
+	t.Run("null placements", func(t *testing.T) {
+		app := setupTestApp(t)
+		defer app.Cleanup()
+
+		seedClientConfig(t, app)
+
+		document, err := readImportFile(writeImportFile(t, "configs.yaml", `
+configs:
+  - name: default
+    experiment_id: control
+    banner_refresh_rate: 90
+    placements:
+`))
+		require.NoError(t, err)
+
+		changes, err := importRecords(app, []*importDocument{document}, false)
+		require.NoError(t, err)
+		assertImportChanges(t, []string{
+			"update advertisement_configs default [control] (banner_refresh_rate)",
+		}, changes)
+
+		placements, err := app.FindAllRecords(advertisementsPlacementsCollectionName, dbx.HashExp{"advertisement_id": findLatestTestConfig(t, app).Id})
+		require.NoError(t, err)
+		assert.Len(t, placements, 2, "A null list should keep the placements")
+
+		document.Configs[0]["placements"] = []any{}
+		changes, err = importRecords(app, []*importDocument{document}, false)
+		require.NoError(t, err)
+		assertImportChanges(t, []string{
+			"delete advertisements_placements default [control] AppReady",
+			"delete advertisements_placements default [control] Button/Hint/Click",
+		}, changes)
+	})
+
+	t.Run("same config in another game", func(t *testing.T) {
+		app := setupTestApp(t)
+		defer app.Cleanup()
+
+		seedClientConfig(t, app)
+		seedCloneGame(t, app)
+
+		document := &importDocument{Configs: []map[string]any{{
+			"name":                "default",
+			"experiment_id":       "control",
+			"game_id":             []any{"studio.sun.puzzle"},
+			"banner_refresh_rate": 90,
+		}}}
+
+		changes, err := importRecords(app, []*importDocument{document}, false)
+		require.NoError(t, err)
+		assertImportChanges(t, []string{"create advertisement_configs default [control]"}, changes)
+
+		source, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+		require.NoError(t, err)
+		assert.True(t, source.GetBool("is_latest"), "The config of the other game should be untouched")
+		assert.Equal(t, 60, source.GetInt("banner_refresh_rate"))
+
+		document.Configs[0]["banner_refresh_rate"] = 30
+		changes, err = importRecords(app, []*importDocument{document}, false)
+		require.NoError(t, err)
+		assertImportChanges(t, []string{"update advertisement_configs default [control] (banner_refresh_rate)"}, changes)
+
+		delete(document.Configs[0], "game_id")
+		_, err = importRecords(app, []*importDocument{document}, false)
+		assert.ErrorContains(t, err, "several games have a config with this name and experiment_id")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		app := setupTestApp(t)
+		defer app.Cleanup()
+
+		seedClientConfig(t, app)
+
+		scenarios := []struct {
+			name     string
+			document importDocument
+			expected string
+		}{
+			{
+				name:     "missing game_id",
+				document: importDocument{Games: []map[string]any{{"name": "Puzzle"}}},
+				expected: "a game_id is required",
+			},
+			{
+				name:     "unknown field",
+				document: importDocument{Games: []map[string]any{{"game_id": "studio.sun.puzzle", "genre": "puzzle"}}},
+				expected: `unknown field "genre"`,
+			},
+			{
+				name:     "invalid bundle id",
+				document: importDocument{Games: []map[string]any{{"game_id": "puzzle"}}},
+				expected: "game_id: must be a reverse-DNS bundle identifier",
+			},
+			{
+				name: "duplicate game",
+				document: importDocument{Games: []map[string]any{
+					{"game_id": "studio.sun.puzzle"},
+					{"game_id": "Studio.Sun.Puzzle"},
+				}},
+				expected: "imported more than once",
+			},
+			{
+				name: "workflow field",
+				document: importDocument{Configs: []map[string]any{
+					{"name": "default", "experiment_id": "control", "status": statusPublished},
+				}},
+				expected: `field "status" can't be imported`,
+			},
+			{
+				name: "unknown game",
+				document: importDocument{Configs: []map[string]any{
+					{"name": "other", "experiment_id": "control", "game_id": []any{"studio.sun.unknown"}},
+				}},
+				expected: `game "studio.sun.unknown" not found`,
+			},
+			{
+				name: "unknown catalog placement",
+				document: importDocument{Configs: []map[string]any{{
+					"name":          "default",
+					"experiment_id": "control",
+					"placements":    []any{map[string]any{"placement_id": "Unknown"}},
+				}}},
+				expected: `placement "Unknown" not found in the catalog`,
+			},
+		}
+
+		for _, scenario := range scenarios {
+			t.Run(scenario.name, func(t *testing.T) {
+				_, err := importRecords(app, []*importDocument{&scenario.document}, false)
+				assert.ErrorContains(t, err, scenario.expected)
+			})
+		}
+
+		_, err := app.FindFirstRecordByData(gamesCollectionName, "game_id", "studio.sun.puzzle")
+		assert.Error(t, err, "A failed import should not write anything")
+	})
+}
+
+func assertImportChanges(t *testing.T, expected []string, changes []importChange) {
+	t.Helper()
+
+	actual := make([]string, 0, len(changes))
+	for _, change := range changes {
+		actual = append(actual, change.String())
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestUniqueIndexes(t *testing.T) {
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedClientConfig(t, app)
+		authenticateAsAdmin(t, app, e)
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "duplicate game bundle identifier",
+			Method: http.MethodPost,
+			URL:    "/api/collections/games/records",
+			Body:   strings.NewReader(`{"game_id":"` + testGameID + `"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"game_id":{"code":"validation_not_unique"`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:   "placement added twice to an advertisement config",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisements_placements/records",
+			Body:   strings.NewReader(`{"advertisement_id":"` + testConfigRecordID + `","placement_id":"` + testCatalogPlacementID + `"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"placement_id":{"code":"validation_not_unique"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				createTestRecord(t, app, placementsCollectionName, map[string]any{
+					"id":   testCatalogPlacementID,
+					"name": "Screen/Shop/Open",
+				})
+				createTestRecord(t, app, advertisementsPlacementsCollectionName, map[string]any{
+					"advertisement_id": testConfigRecordID,
+					"placement_id":     testCatalogPlacementID,
+				})
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "duplicate advertisement config name in an experiment",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body:   strings.NewReader(`{"name":"default","experiment_id":"control","game_id":["` + testGameRecordID + `"]}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"name":{"code":"validation_not_unique"`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:   "same advertisement config name in another experiment",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body:   strings.NewReader(`{"name":"default","experiment_id":"other","game_id":["` + testGameRecordID + `"]}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"name":"default"`, `"experiment_id":"other"`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:   "same advertisement config name and experiment in another game",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body:   strings.NewReader(`{"name":"default","experiment_id":"control","game_id":["` + testCloneGameRecordID + `"]}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"name":"default"`, `"experiment_id":"control"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				seedCloneGame(t, app)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -103,7 +103,7 @@ func waitForPocketBaseReady() error {
 
 // testPocketBaseAuthenticationAndCollections tests authentication and verifies the system is properly set up
 func testPocketBaseAuthenticationAndCollections() error {
-	// Admin credentials (from the migration)
+	// Admin credentials (from the development compose file)
 	adminEmail := "admin@sun.studio"
 	adminPassword := "adminpassword123"
 
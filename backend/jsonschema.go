@@ -0,0 +1,359 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchemaError describes a single JSON Schema violation.
+type jsonSchemaError struct {
+	// Path is the JSON pointer (RFC 6901) of the failing value.
+	Path    string
+	Message string
+}
+
+// jsonSchemaTypes lists the supported values of the "type" keyword.
+var jsonSchemaTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+// jsonSchemaAnnotations lists the keywords that are accepted but don't affect validation.
+var jsonSchemaAnnotations = []string{"$schema", "$id", "title", "description", "default", "examples", "$comment"}
+
+// validateJSONSchema validates value against the provided JSON Schema and
+// returns every violation found.
+//
+// Only the subset of the specification checked by checkJSONSchema is supported.
+func validateJSONSchema(schema any, value any) []jsonSchemaError {
+	var errs []jsonSchemaError
+	validateJSONSchemaValue(schema, value, "", &errs)
+
+	return errs
+}
+
+func validateJSONSchemaValue(schema any, value any, path string, errs *[]jsonSchemaError) {
+	if allowed, ok := schema.(bool); ok {
+		if !allowed {
+			*errs = append(*errs, jsonSchemaError{path, "value is not allowed"})
+		}
+		return
+	}
+
+	rules, ok := schema.(map[string]any)
+	if !ok {
+		return
+	}
+
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, jsonSchemaError{path, fmt.Sprintf(format, args...)})
+	}
+
+	if t, ok := rules["type"]; ok && !matchesJSONType(t, value) {
+		fail("must be of type %s", describeJSONType(t))
+		return // the remaining keywords are type specific
+	}
+
+	if enum, ok := rules["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(v any) bool { return reflect.DeepEqual(v, value) }) {
+			fail("must be one of %s", mustMarshalJSON(enum))
+		}
+	}
+
+	if c, ok := rules["const"]; ok && !reflect.DeepEqual(c, value) {
+		fail("must be equal to %s", mustMarshalJSON(c))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		validateJSONSchemaObject(rules, v, path, errs)
+	case []any:
+		validateJSONSchemaArray(rules, v, path, errs)
+	case string:
+		length := utf8.RuneCountInString(v)
+		if min, ok := rules["minLength"].(float64); ok && float64(length) < min {
+			fail("must be at least %v characters long", min)
+		}
+		if max, ok := rules["maxLength"].(float64); ok && float64(length) > max {
+			fail("must be at most %v characters long", max)
+		}
+		if pattern, ok := rules["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				fail("must match the pattern %q", pattern)
+			}
+		}
+	case float64:
+		if min, ok := rules["minimum"].(float64); ok && v < min {
+			fail("must be greater than or equal to %v", min)
+		}
+		if max, ok := rules["maximum"].(float64); ok && v > max {
+			fail("must be less than or equal to %v", max)
+		}
+		if min, ok := rules["exclusiveMinimum"].(float64); ok && v <= min {
+			fail("must be greater than %v", min)
+		}
+		if max, ok := rules["exclusiveMaximum"].(float64); ok && v >= max {
+			fail("must be less than %v", max)
+		}
+	}
+}
+
+func validateJSONSchemaObject(rules map[string]any, value map[string]any, path string, errs *[]jsonSchemaError) {
+	if required, ok := rules["required"].([]any); ok {
+		for _, name := range required {
+			key, _ := name.(string)
+			if _, exists := value[key]; !exists {
+				*errs = append(*errs, jsonSchemaError{
+					jsonPointerChild(path, key),
+					"is required",
+				})
+			}
+		}
+	}
+
+	properties, _ := rules["properties"].(map[string]any)
+	additional, hasAdditional := rules["additionalProperties"]
+
+	for _, key := range sortedKeys(value) {
+		childPath := jsonPointerChild(path, key)
+		if propertySchema, ok := properties[key]; ok {
+			validateJSONSchemaValue(propertySchema, value[key], childPath, errs)
+			continue
+		}
+		if !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok && !allowed {
+			*errs = append(*errs, jsonSchemaError{childPath, "is not an allowed property"})
+			continue
+		}
+		validateJSONSchemaValue(additional, value[key], childPath, errs)
+	}
+}
+
+func validateJSONSchemaArray(rules map[string]any, value []any, path string, errs *[]jsonSchemaError) {
+	if min, ok := rules["minItems"].(float64); ok && float64(len(value)) < min {
+		*errs = append(*errs, jsonSchemaError{path, fmt.Sprintf("must have at least %v items", min)})
+	}
+	if max, ok := rules["maxItems"].(float64); ok && float64(len(value)) > max {
+		*errs = append(*errs, jsonSchemaError{path, fmt.Sprintf("must have at most %v items", max)})
+	}
+	if unique, _ := rules["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					*errs = append(*errs, jsonSchemaError{
+						jsonPointerChild(path, strconv.Itoa(i)),
+						fmt.Sprintf("duplicates item %d", j),
+					})
+					break
+				}
+			}
+		}
+	}
+	if items, ok := rules["items"]; ok {
+		for i, item := range value {
+			validateJSONSchemaValue(items, item, jsonPointerChild(path, strconv.Itoa(i)), errs)
+		}
+	}
+}
+
+// checkJSONSchema validates that schema is itself a JSON Schema supported by
+// validateJSONSchema, reporting unknown keywords so that typos don't silently
+// disable a rule.
+func checkJSONSchema(schema any) []jsonSchemaError {
+	var errs []jsonSchemaError
+	checkJSONSchemaValue(schema, "", &errs)
+
+	return errs
+}
+
+func checkJSONSchemaValue(schema any, path string, errs *[]jsonSchemaError) {
+	fail := func(keywordPath string, format string, args ...any) {
+		*errs = append(*errs, jsonSchemaError{keywordPath, fmt.Sprintf(format, args...)})
+	}
+
+	if _, ok := schema.(bool); ok {
+		return
+	}
+
+	rules, ok := schema.(map[string]any)
+	if !ok {
+		fail(path, "must be an object or a boolean")
+		return
+	}
+
+	for _, keyword := range sortedKeys(rules) {
+		value := rules[keyword]
+		keywordPath := jsonPointerChild(path, keyword)
+
+		switch keyword {
+		case "type":
+			if !isValidJSONSchemaType(value) {
+				fail(keywordPath, "must be one of %s or an array of them", strings.Join(jsonSchemaTypes, ", "))
+			}
+		case "enum":
+			if _, ok := value.([]any); !ok {
+				fail(keywordPath, "must be an array")
+			}
+		case "const":
+		case "properties":
+			properties, ok := value.(map[string]any)
+			if !ok {
+				fail(keywordPath, "must be an object")
+				continue
+			}
+			for _, name := range sortedKeys(properties) {
+				checkJSONSchemaValue(properties[name], jsonPointerChild(keywordPath, name), errs)
+			}
+		case "required":
+			if !isJSONStringArray(value) {
+				fail(keywordPath, "must be an array of strings")
+			}
+		case "additionalProperties", "items":
+			checkJSONSchemaValue(value, keywordPath, errs)
+		case "minLength", "maxLength", "minItems", "maxItems":
+			if n, ok := value.(float64); !ok || n < 0 || n != math.Trunc(n) {
+				fail(keywordPath, "must be a non-negative integer")
+			}
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			if _, ok := value.(float64); !ok {
+				fail(keywordPath, "must be a number")
+			}
+		case "uniqueItems":
+			if _, ok := value.(bool); !ok {
+				fail(keywordPath, "must be a boolean")
+			}
+		case "pattern":
+			pattern, ok := value.(string)
+			if !ok {
+				fail(keywordPath, "must be a string")
+			} else if _, err := regexp.Compile(pattern); err != nil {
+				fail(keywordPath, "must be a valid regular expression")
+			}
+		default:
+			if !slices.Contains(jsonSchemaAnnotations, keyword) {
+				fail(keywordPath, "unsupported keyword %q", keyword)
+			}
+		}
+	}
+}
+
+func isValidJSONSchemaType(t any) bool {
+	switch v := t.(type) {
+	case string:
+		return slices.Contains(jsonSchemaTypes, v)
+	case []any:
+		if len(v) == 0 {
+			return false
+		}
+		for _, item := range v {
+			if s, ok := item.(string); !ok || !slices.Contains(jsonSchemaTypes, s) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func isJSONStringArray(value any) bool {
+	items, ok := value.([]any)
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		if _, ok := item.(string); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesJSONType(t any, value any) bool {
+	switch v := t.(type) {
+	case string:
+		return matchesSingleJSONType(v, value)
+	case []any:
+		return slices.ContainsFunc(v, func(item any) bool {
+			s, _ := item.(string)
+			return matchesSingleJSONType(s, value)
+		})
+	}
+
+	return true
+}
+
+func matchesSingleJSONType(t string, value any) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+
+	return false
+}
+
+func describeJSONType(t any) string {
+	if types, ok := t.([]any); ok {
+		names := make([]string, 0, len(types))
+		for _, item := range types {
+			names = append(names, fmt.Sprint(item))
+		}
+		return strings.Join(names, " or ")
+	}
+
+	return fmt.Sprint(t)
+}
+
+// sortedKeys returns the map keys in sorted order so that the errors are
+// reported deterministically.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// jsonPointerChild appends an escaped reference token to a JSON pointer.
+func jsonPointerChild(pointer string, token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+
+	return pointer + "/" + token
+}
+
+func mustMarshalJSON(v any) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(raw)
+}
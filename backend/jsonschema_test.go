@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdSettingsSchema = `{
+	"type": "object",
+	"required": ["banner", "refresh_rate"],
+	"additionalProperties": false,
+	"properties": {
+		"banner": {"type": "boolean"},
+		"refresh_rate": {"type": "integer", "minimum": 30, "maximum": 120},
+		"network": {"enum": ["admob", "applovin"]},
+		"placements": {
+			"type": "array",
+			"uniqueItems": true,
+			"items": {"type": "string", "pattern": "^[A-Z]"}
+		},
+		"a/b": {"type": "string", "maxLength": 3}
+	}
+}`
+
+func decodeTestJSON(t *testing.T, raw string) any {
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(raw), &value), "Failed to decode %s", raw)
+
+	return value
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := decodeTestJSON(t, testAdSettingsSchema)
+
+	scenarios := []struct {
+		name     string
+		data     string
+		expected []jsonSchemaError
+	}{
+		{
+			name: "valid document",
+			data: `{"banner": true, "refresh_rate": 60, "network": "admob", "placements": ["AppReady"]}`,
+		},
+		{
+			name: "wrong root type",
+			data: `[]`,
+			expected: []jsonSchemaError{
+				{"", "must be of type object"},
+			},
+		},
+		{
+			name: "every failing path is reported",
+			data: `{"banner": "yes", "refresh_rate": 60.5, "netwrok": "admob", "placements": ["AppReady", "level", "AppReady"], "a/b": "long"}`,
+			expected: []jsonSchemaError{
+				{"/a~1b", "must be at most 3 characters long"},
+				{"/banner", "must be of type boolean"},
+				{"/netwrok", "is not an allowed property"},
+				{"/placements/2", "duplicates item 0"},
+				{"/placements/1", `must match the pattern "^[A-Z]"`},
+				{"/refresh_rate", "must be of type integer"},
+			},
+		},
+		{
+			name: "missing and out of range values",
+			data: `{"refresh_rate": 10, "network": "unity"}`,
+			expected: []jsonSchemaError{
+				{"/banner", "is required"},
+				{"/network", `must be one of ["admob","applovin"]`},
+				{"/refresh_rate", "must be greater than or equal to 30"},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := validateJSONSchema(schema, decodeTestJSON(t, s.data))
+			assert.Equal(t, s.expected, errs)
+		})
+	}
+}
+
+func TestCheckJSONSchema(t *testing.T) {
+	assert.Empty(t, checkJSONSchema(decodeTestJSON(t, testAdSettingsSchema)))
+
+	errs := checkJSONSchema(decodeTestJSON(t, `{
+		"type": "dictionary",
+		"requried": ["banner"],
+		"properties": {
+			"banner": {"type": "boolean", "minLength": -1},
+			"name": {"pattern": "("}
+		}
+	}`))
+
+	assert.Equal(t, []jsonSchemaError{
+		{"/properties/banner/minLength", "must be a non-negative integer"},
+		{"/properties/name/pattern", "must be a valid regular expression"},
+		{"/requried", `unsupported keyword "requried"`},
+		{"/type", "must be one of object, array, string, number, integer, boolean, null or an array of them"},
+	}, errs)
+}
@@ -1,19 +1,41 @@
 package main
 
 import (
+	"cmp"
 	_ "config-manager/pb_migrations" // Import migrations to register them
+	"encoding/json"
 	"log/slog"
 	"os"
 	"path"
 	"regexp"
 	"runtime"
+	"slices"
+	"strings"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/plugins/migratecmd"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cobra"
 )
 
+const (
+	gamesCollectionName                    = "games"
+	advertisementConfigsCollectionName     = "advertisement_configs"
+	advertisementsPlacementsCollectionName = "advertisements_placements"
+	configurationTemplatesCollectionName   = "configuration_templates"
+	configurationsCollectionName           = "configurations"
+	experimentsCollectionName              = "experiments"
+	placementsCollectionName               = "placements"
+	gameMembersCollectionName              = "game_members"
+	usersCollectionName                    = "users"
+	apiKeysCollectionName                  = "api_keys"
+	auditLogCollectionName                 = "audit_log"
+	promotionsCollectionName               = "promotions"
+)
+
 func makeApp() *pocketbase.PocketBase {
 	isDev := os.Getenv("PB_DEV") == "true"
 
@@ -41,8 +63,78 @@ func configMigration(app core.App, cmd *cobra.Command) {
 }
 
 func configHooks(app core.App) {
-	app.OnRecordCreateRequest("configuration_templates").BindFunc(validateConfigurationTemplateName)
-	app.OnRecordUpdateRequest("configuration_templates").BindFunc(validateConfigurationTemplateName)
+	// every change of the game scoped records is recorded in the append-only audit log
+	app.OnRecordCreateRequest(auditedCollections...).BindFunc(auditRecordCreate)
+	app.OnRecordUpdateRequest(auditedCollections...).BindFunc(auditRecordUpdate)
+	app.OnRecordDeleteRequest(auditedCollections...).BindFunc(auditRecordDelete)
+	app.OnRecordCreateRequest(auditLogCollectionName).BindFunc(preventAuditLogChanges)
+	app.OnRecordUpdateRequest(auditLogCollectionName).BindFunc(preventAuditLogChanges)
+	app.OnRecordDeleteRequest(auditLogCollectionName).BindFunc(preventAuditLogChanges)
+
+	// the game scoped records can be changed according to the member role in their games
+	app.OnRecordCreateRequest(gamesCollectionName).BindFunc(addGameOwner)
+	app.OnRecordUpdateRequest(gamesCollectionName, gameMembersCollectionName).BindFunc(requireOwner)
+	app.OnRecordDeleteRequest(gamesCollectionName, gameMembersCollectionName).BindFunc(requireOwner)
+	app.OnRecordCreateRequest(gameMembersCollectionName).BindFunc(requireOwner)
+	app.OnRecordCreateRequest(advertisementConfigsCollectionName).BindFunc(requireEditor)
+	app.OnRecordUpdateRequest(advertisementConfigsCollectionName).BindFunc(requireAdConfigUpdateRole)
+	app.OnRecordDeleteRequest(advertisementConfigsCollectionName).BindFunc(requireOwner)
+	app.OnRecordCreateRequest(advertisementsPlacementsCollectionName).BindFunc(requireEditor)
+	app.OnRecordUpdateRequest(advertisementsPlacementsCollectionName).BindFunc(requireEditor)
+	app.OnRecordDeleteRequest(advertisementsPlacementsCollectionName).BindFunc(requireEditor)
+	app.OnRecordCreateRequest(editorCollections...).BindFunc(requireEditor)
+	app.OnRecordUpdateRequest(editorCollections...).BindFunc(requireEditor)
+	app.OnRecordDeleteRequest(editorCollections...).BindFunc(requireEditor)
+	app.OnRecordCreateRequest(placementsCollectionName).BindFunc(requirePlacementCatalogEditor)
+	app.OnRecordUpdateRequest(placementsCollectionName).BindFunc(requirePlacementCatalogEditor)
+	app.OnRecordDeleteRequest(placementsCollectionName).BindFunc(requirePlacementCatalogEditor)
+
+	app.OnRecordCreateRequest(configurationTemplatesCollectionName).BindFunc(validateConfigurationTemplateName)
+	app.OnRecordUpdateRequest(configurationTemplatesCollectionName).BindFunc(validateConfigurationTemplateName)
+	app.OnRecordCreateRequest(configurationTemplatesCollectionName).BindFunc(validateConfigurationTemplateSchema)
+	app.OnRecordUpdateRequest(configurationTemplatesCollectionName).BindFunc(validateConfigurationTemplateSchema)
+
+	app.OnRecordCreateRequest(configurationsCollectionName).BindFunc(validateConfigurationData)
+	app.OnRecordUpdateRequest(configurationsCollectionName).BindFunc(validateConfigurationData)
+
+	app.OnRecordCreateRequest(gamesCollectionName).BindFunc(normalizeGameBundleIDs)
+	app.OnRecordUpdateRequest(gamesCollectionName).BindFunc(normalizeGameBundleIDs)
+	app.OnRecordCreateRequest(gamesCollectionName).BindFunc(startGameDevelopment)
+
+	for collection := range recordValidators {
+		app.OnRecordCreateRequest(collection).BindFunc(validateRecord)
+		app.OnRecordUpdateRequest(collection).BindFunc(validateRecord)
+	}
+
+	// advertisement configs go through the draft -> review -> published workflow
+	app.OnRecordCreateRequest(advertisementConfigsCollectionName).BindFunc(startAdConfigDraft)
+	app.OnRecordUpdateRequest(advertisementConfigsCollectionName).BindFunc(enforceAdConfigWorkflow)
+
+	// every save of a configuration or advertisement config creates a new version
+	app.OnRecordCreateRequest(configurationsCollectionName, advertisementConfigsCollectionName).BindFunc(createFirstVersion)
+	app.OnRecordUpdateRequest(configurationsCollectionName, advertisementConfigsCollectionName).BindFunc(createVersionOnUpdate)
+	app.OnRecordDeleteRequest(configurationsCollectionName, advertisementConfigsCollectionName).BindFunc(deleteVersionLineage)
+	app.OnRecordCreateRequest(advertisementsPlacementsCollectionName).BindFunc(versionPlacementCreate)
+	app.OnRecordUpdateRequest(advertisementsPlacementsCollectionName).BindFunc(versionPlacementUpdate)
+	app.OnRecordDeleteRequest(advertisementsPlacementsCollectionName).BindFunc(versionPlacementDelete)
+}
+
+func configRoutes(app core.App) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/api/client-config/{game_id}", handleClientConfig).BindFunc(requireGameAPIKey(apiKeyScopeClient))
+		se.Router.POST("/api/configs/{id}/rollback", handleConfigRollback).Bind(apis.RequireAuth())
+		se.Router.GET("/api/configs/diff", handleConfigDiff).Bind(apis.RequireAuth())
+		se.Router.POST("/api/advertisement_configs/{id}/clone", handleCloneAdvertisementConfig).Bind(apis.RequireAuth())
+		se.Router.GET("/api/games/overview", handleGamesOverview).Bind(apis.RequireAuth())
+		se.Router.POST("/api/games/{id}/api-keys", handleCreateAPIKey).Bind(apis.RequireAuth())
+		se.Router.POST("/api/api-keys/{id}/rotate", handleRotateAPIKey).Bind(apis.RequireAuth())
+		se.Router.POST("/api/api-keys/{id}/revoke", handleRevokeAPIKey).Bind(apis.RequireAuth())
+		se.Router.GET("/api/audit-log", handleAuditLog).Bind(apis.RequireAuth())
+		se.Router.GET("/api/promotion/{game_id}", handlePublishedConfigs).BindFunc(requireGameAPIKey(apiKeyScopePromotion))
+		se.Router.POST("/api/games/{id}/promote", handlePromoteGame).Bind(apis.RequireAuth())
+
+		return se.Next()
+	})
 }
 
 func validateConfigurationTemplateName(e *core.RecordRequestEvent) error {
@@ -60,10 +152,105 @@ func validateConfigurationTemplateName(e *core.RecordRequestEvent) error {
 	return e.Next()
 }
 
+func validateConfigurationTemplateSchema(e *core.RecordRequestEvent) error {
+	schema, err := recordJSONValue(e.Record, "schema")
+	if err != nil {
+		return e.BadRequestError("configuration template schema must be valid JSON", nil)
+	}
+
+	// Templates without schema accept any configuration data
+	if schema == nil {
+		return e.Next()
+	}
+
+	if errs := checkJSONSchema(schema); len(errs) > 0 {
+		return e.BadRequestError(
+			"configuration template schema is invalid at "+jsonSchemaErrorPaths(errs),
+			validation.Errors{"schema": jsonSchemaValidationErrors(errs)},
+		)
+	}
+
+	return e.Next()
+}
+
+func validateConfigurationData(e *core.RecordRequestEvent) error {
+	template, err := e.App.FindRecordById(configurationTemplatesCollectionName, e.Record.GetString("template_id"))
+	if err != nil {
+		// let the record validation report the invalid relation
+		return e.Next()
+	}
+
+	schema, err := recordJSONValue(template, "schema")
+	if err != nil || schema == nil {
+		return e.Next()
+	}
+
+	data, err := recordJSONValue(e.Record, "data")
+	if err != nil {
+		return e.BadRequestError("configuration data must be valid JSON", nil)
+	}
+
+	if errs := validateJSONSchema(schema, data); len(errs) > 0 {
+		return e.BadRequestError(
+			"configuration data doesn't match the template schema at "+jsonSchemaErrorPaths(errs),
+			validation.Errors{"data": jsonSchemaValidationErrors(errs)},
+		)
+	}
+
+	return e.Next()
+}
+
+// recordJSONValue decodes the value of a JSON field, returning nil for empty fields.
+func recordJSONValue(record *core.Record, field string) (any, error) {
+	raw, _ := record.Get(field).(types.JSONRaw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// jsonSchemaErrorPaths lists the unique failing JSON pointers, the document root being shown as "/".
+func jsonSchemaErrorPaths(errs []jsonSchemaError) string {
+	paths := make([]string, 0, len(errs))
+	for _, err := range errs {
+		path := cmp.Or(err.Path, "/")
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	return strings.Join(paths, ", ")
+}
+
+// jsonSchemaValidationErrors converts the schema violations into the
+// PocketBase error data format keyed by JSON pointer.
+func jsonSchemaValidationErrors(errs []jsonSchemaError) validation.Errors {
+	result := validation.Errors{}
+	for _, err := range errs {
+		path := cmp.Or(err.Path, "/")
+		message := err.Message
+		if existing, ok := result[path]; ok {
+			message = existing.Error() + "; " + message
+		}
+		result[path] = validation.NewError("validation_json_schema", message)
+	}
+
+	return result
+}
+
 func main() {
 	app := makeApp()
 	configMigration(app, app.RootCmd)
+	app.RootCmd.AddCommand(newExportCommand(app), newImportCommand(app), newPromoteCommand(app))
 	configHooks(app)
+	configRoutes(app)
+	app.OnServe().BindFunc(refuseDefaultCredentials)
 
 	// Bootstrap the app (initializes database and runs migrations)
 	slog.Info("bootstrapping PocketBase")
@@ -82,21 +82,6 @@ func TestSuperuserAuthentication(t *testing.T) {
 }
 
 func TestRecordCreationWithInvalidAuthToken(t *testing.T) {
-	// set up the test ApiScenario app instance
-	setupTestApp := func(t testing.TB) *tests.TestApp {
-		testApp, err := tests.NewTestAppWithConfig(
-			core.BaseAppConfig{
-				PostgresURL: os.Getenv("POSTGRES_URL"),
-			},
-		)
-		assert.NoError(t, err, "Failed to create test app")
-
-		configMigration(testApp, nil)
-		configHooks(testApp)
-
-		return testApp
-	}
-
 	// Define test scenarios for record creation with invalid auth token
 	scenarios := []*tests.ApiScenario{
 		{
@@ -133,6 +118,22 @@ func TestRecordCreationWithInvalidAuthToken(t *testing.T) {
 	}
 }
 
+// setupTestApp sets up the test ApiScenario app instance
+func setupTestApp(t testing.TB) *tests.TestApp {
+	testApp, err := tests.NewTestAppWithConfig(
+		core.BaseAppConfig{
+			PostgresURL: os.Getenv("POSTGRES_URL"),
+		},
+	)
+	assert.NoError(t, err, "Failed to create test app")
+
+	configMigration(testApp, nil)
+	configHooks(testApp)
+	configRoutes(testApp)
+
+	return testApp
+}
+
 const adminEmail = "admin@sun.studio"
 
 func getToken(app *tests.TestApp) (string, error) {
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// gameOverview summarizes the configuration of a single game for the
+// dashboard.
+type gameOverview struct {
+	ID                string         `json:"id"`
+	GameID            string         `json:"game_id"`
+	Name              string         `json:"name"`
+	Status            string         `json:"status"`
+	AdConfigs         int            `json:"ad_configs"`
+	Placements        int            `json:"placements"`
+	ActiveExperiments int            `json:"active_experiments"`
+	LastModified      types.DateTime `json:"last_modified"`
+}
+
+type gamesOverviewTotals struct {
+	Games             int `json:"games"`
+	LiveGames         int `json:"live_games"`
+	AdConfigs         int `json:"ad_configs"`
+	Placements        int `json:"placements"`
+	ActiveExperiments int `json:"active_experiments"`
+}
+
+type gamesOverview struct {
+	Games  []*gameOverview     `json:"games"`
+	Totals gamesOverviewTotals `json:"totals"`
+}
+
+// handleGamesOverview serves the per-game counts of advertisement configs,
+// placements and running experiments.
+func handleGamesOverview(e *core.RequestEvent) error {
+	games, err := findMemberGames(e)
+	if err != nil {
+		return e.InternalServerError("failed to find the games", err)
+	}
+
+	overview, err := buildGamesOverview(e.App, games)
+	if err != nil {
+		return e.InternalServerError("failed to build the games overview", err)
+	}
+
+	return e.JSON(http.StatusOK, overview)
+}
+
+// findMemberGames returns the games the request auth record is a member of,
+// superusers seeing every game.
+func findMemberGames(e *core.RequestEvent) ([]*core.Record, error) {
+	if e.HasSuperuserAuth() {
+		return e.App.FindRecordsByFilter(gamesCollectionName, "", "game_id", 0, 0)
+	}
+
+	return e.App.FindRecordsByFilter(
+		gamesCollectionName,
+		"game_members_via_game_id.user ?= {:user}",
+		"game_id",
+		0,
+		0,
+		dbx.Params{"user": e.Auth.Id},
+	)
+}
+
+// buildGamesOverview aggregates the latest advertisement config versions
+// (and their placements) and the experiments of the given games.
+//
+// The last modified time of a game is the most recent update of the game
+// itself, of its advertisement configs, placements or experiments.
+func buildGamesOverview(app core.App, games []*core.Record) (*gamesOverview, error) {
+	overview := &gamesOverview{Games: make([]*gameOverview, len(games))}
+	byID := make(map[string]*gameOverview, len(games))
+	for i, game := range games {
+		item := &gameOverview{
+			ID:           game.Id,
+			GameID:       game.GetString("game_id"),
+			Name:         game.GetString("name"),
+			Status:       game.GetString("status"),
+			LastModified: game.GetDateTime("updated"),
+		}
+		overview.Games[i] = item
+		byID[game.Id] = item
+
+		overview.Totals.Games++
+		if item.Status == gameStatusLive {
+			overview.Totals.LiveGames++
+		}
+	}
+
+	touch := func(item *gameOverview, updated types.DateTime) {
+		if updated.After(item.LastModified) {
+			item.LastModified = updated
+		}
+	}
+
+	configs, err := app.FindAllRecords(advertisementConfigsCollectionName, dbx.HashExp{"is_latest": true})
+	if err != nil {
+		return nil, err
+	}
+
+	configIDs := make([]any, len(configs))
+	for i, config := range configs {
+		configIDs[i] = config.Id
+	}
+
+	placements, err := app.FindAllRecords(advertisementsPlacementsCollectionName, dbx.In("advertisement_id", configIDs...))
+	if err != nil {
+		return nil, err
+	}
+
+	placementsByConfig := map[string][]*core.Record{}
+	for _, placement := range placements {
+		configID := placement.GetString("advertisement_id")
+		placementsByConfig[configID] = append(placementsByConfig[configID], placement)
+	}
+
+	for _, config := range configs {
+		counted := false
+		for _, gameID := range configGameIDs(config) {
+			item, ok := byID[gameID]
+			if !ok {
+				continue
+			}
+
+			// a config shared by several games is counted once in the totals
+			if !counted {
+				overview.Totals.AdConfigs++
+				overview.Totals.Placements += len(placementsByConfig[config.Id])
+				counted = true
+			}
+
+			item.AdConfigs++
+			touch(item, config.GetDateTime("updated"))
+			for _, placement := range placementsByConfig[config.Id] {
+				item.Placements++
+				touch(item, placement.GetDateTime("updated"))
+			}
+		}
+	}
+
+	experiments, err := app.FindAllRecords(experimentsCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, experiment := range experiments {
+		item, ok := byID[experiment.GetString("game_id")]
+		if !ok {
+			continue
+		}
+
+		touch(item, experiment.GetDateTime("updated"))
+		if experiment.GetBool("running") {
+			item.ActiveExperiments++
+			overview.Totals.ActiveExperiments++
+		}
+	}
+
+	return overview, nil
+}
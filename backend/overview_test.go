@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGamesOverview(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "requires authentication",
+			Method:          http.MethodGet,
+			URL:             "/api/games/overview",
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:           "per game counts",
+			Method:         http.MethodGet,
+			URL:            "/api/games/overview",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"id":"` + testGameRecordID + `","game_id":"studio.sun.rpg"`,
+				`"ad_configs":2,"placements":2,"active_experiments":1`,
+				`"totals":{"games":1,"live_games":0,"ad_configs":2,"placements":2,"active_experiments":1}`,
+			},
+			// only the games of the user are summarized
+			NotExpectedContent: []string{"othergame000001"},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedExperiment(t, app, true, 50, 50)
+				createTestRecord(t, app, gamesCollectionName, map[string]any{
+					"id":      "othergame000001",
+					"game_id": "studio.sun.other",
+					"name":    "Other",
+					"status":  gameStatusDevelopment,
+				})
+				// the previous versions are not counted
+				createTestRecord(t, app, advertisementConfigsCollectionName, map[string]any{
+					"lineage_id":    testVariantConfigRecordID,
+					"version":       0,
+					"is_latest":     false,
+					"status":        statusArchived,
+					"name":          "aggressive",
+					"experiment_id": "control",
+					"game_id":       []string{testGameRecordID},
+				})
+				seedGameMember(t, app, roleViewer)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "superusers summarize every game",
+			Method:         http.MethodGet,
+			URL:            "/api/games/overview",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"id":"othergame000001","game_id":"studio.sun.other","name":"Other","status":"development","ad_configs":0,"placements":0,"active_experiments":0`,
+				`"totals":{"games":2,"live_games":0,"ad_configs":1,"placements":2,"active_experiments":0}`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				createTestRecord(t, app, gamesCollectionName, map[string]any{
+					"id":      "othergame000001",
+					"game_id": "studio.sun.other",
+					"name":    "Other",
+					"status":  gameStatusDevelopment,
+				})
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestGamesOverviewLastModified(t *testing.T) {
+	app := setupTestApp(t)
+	defer app.Cleanup()
+
+	seedClientConfig(t, app)
+
+	game, err := app.FindRecordById(gamesCollectionName, testGameRecordID)
+	require.NoError(t, err)
+
+	placement, err := app.FindRecordById(advertisementsPlacementsCollectionName, testPlacementRecordID)
+	require.NoError(t, err)
+	placement.Set("retry", 5)
+	require.NoError(t, app.Save(placement))
+
+	games, err := app.FindAllRecords(gamesCollectionName)
+	require.NoError(t, err)
+
+	overview, err := buildGamesOverview(app, games)
+	require.NoError(t, err)
+	require.Len(t, overview.Games, 1)
+
+	placement, err = app.FindRecordById(advertisementsPlacementsCollectionName, testPlacementRecordID)
+	require.NoError(t, err)
+	assert.Equal(t, placement.GetDateTime("updated"), overview.Games[0].LastModified)
+	assert.True(t, overview.Games[0].LastModified.After(game.GetDateTime("updated")))
+}
@@ -1,16 +1,56 @@
 package pb_migrations
 
 import (
+	"fmt"
 	"log/slog"
+	"os"
+	"strings"
 
 	"github.com/pocketbase/pocketbase/core"
 	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/security"
 )
 
+// initialEmail returns the email of an initial account from the
+// <prefix>_EMAIL environment variable.
+func initialEmail(prefix string, defaultEmail string) string {
+	if email := os.Getenv(prefix + "_EMAIL"); email != "" {
+		return email
+	}
+
+	return defaultEmail
+}
+
+// initialPassword returns the password of an initial account from the
+// <prefix>_PASSWORD environment variable or from the file named by
+// <prefix>_PASSWORD_FILE (e.g. a Docker secret).
+//
+// A random password is generated and printed once when none is provided.
+func initialPassword(prefix string, email string) (string, error) {
+	password := os.Getenv(prefix + "_PASSWORD")
+	if file := os.Getenv(prefix + "_PASSWORD_FILE"); file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s_PASSWORD_FILE: %w", prefix, err)
+		}
+		password = strings.TrimSpace(string(content))
+	}
+
+	if password == "" {
+		password = security.RandomString(24)
+		fmt.Fprintf(os.Stderr, "Generated password of %s: %s (it won't be printed again)\n", email, password)
+	}
+
+	return password, nil
+}
+
 func init() {
-	const adminEmail = "admin@sun.studio"
-	const userEmail = "moderator@sun.studio"
+	const defaultAdminEmail = "admin@sun.studio"
+	const defaultUserEmail = "moderator@sun.studio"
 	m.Register(func(app core.App) error {
+		adminEmail := initialEmail("PB_ADMIN", defaultAdminEmail)
+		userEmail := initialEmail("PB_USER", defaultUserEmail)
+
 		// Migration logic: Create default admin user
 		slog.Info("running migration", "migration", "create_admin")
 
@@ -28,10 +68,15 @@ func init() {
 			return err
 		}
 
+		adminPassword, err := initialPassword("PB_ADMIN", adminEmail)
+		if err != nil {
+			return err
+		}
+
 		// Create the admin user record
 		record = core.NewRecord(superusers)
 		record.Set("email", adminEmail)
-		record.Set("password", "adminpassword123")
+		record.Set("password", adminPassword)
 		record.Set("verified", true)
 
 		// Save the record
@@ -54,10 +99,15 @@ func init() {
 			return err
 		}
 
+		userPassword, err := initialPassword("PB_USER", userEmail)
+		if err != nil {
+			return err
+		}
+
 		// Create the admin user record
 		record = core.NewRecord(users)
 		record.Set("email", userEmail)
-		record.Set("password", "userpassword123")
+		record.Set("password", userPassword)
 		record.Set("verified", true)
 
 		// Save the record
@@ -70,6 +120,9 @@ func init() {
 
 		return nil
 	}, func(app core.App) error {
+		adminEmail := initialEmail("PB_ADMIN", defaultAdminEmail)
+		userEmail := initialEmail("PB_USER", defaultUserEmail)
+
 		// Rollback logic: Remove the admin user we created
 		slog.Info("rolling back migration", "migration", "create_admin")
 
@@ -0,0 +1,63 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const gamesClientKeyFieldName = "client_key"
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		if collection.Fields.GetByName(gamesClientKeyFieldName) != nil {
+			return nil // field already exists
+		}
+
+		// Add client_key field used by shipped game builds to read their config.
+		// It is hidden so that only superusers can see it in the API responses.
+		clientKeyField := &core.TextField{
+			Name:                gamesClientKeyFieldName,
+			Hidden:              true,
+			Min:                 32,
+			Max:                 32,
+			AutogeneratePattern: "[a-zA-Z0-9]{32}",
+		}
+		collection.Fields.Add(clientKeyField)
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Back-fill keys for the games that already exist
+		records, err := app.FindAllRecords(collection)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if record.GetString(gamesClientKeyFieldName) != "" {
+				continue
+			}
+			record.Set(gamesClientKeyFieldName, security.RandomString(32))
+			if err := app.Save(record); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		collection.Fields.RemoveByName(gamesClientKeyFieldName)
+
+		return app.Save(collection)
+	})
+}
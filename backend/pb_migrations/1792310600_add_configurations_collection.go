@@ -0,0 +1,105 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const configurationsCollectionName = "configurations"
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Check if collection already exists
+		existing, err := app.FindCollectionByNameOrId(configurationsCollectionName)
+		if err == nil && existing != nil {
+			return nil // collection already exists
+		}
+
+		// create configurations collection
+		collection := core.NewBaseCollection(configurationsCollectionName)
+
+		// Add name field
+		nameField := &core.TextField{
+			Name:     "name",
+			Required: true,
+		}
+		collection.Fields.Add(nameField)
+
+		// Add game_id relation field that references games
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+		gameIdField := &core.RelationField{
+			Name:          "game_id",
+			Required:      true,
+			CollectionId:  games.Id,
+			CascadeDelete: true,
+		}
+		collection.Fields.Add(gameIdField)
+
+		// Add template_id relation field that references configuration_templates
+		templates, err := app.FindCollectionByNameOrId(configurationTemplatesCollectionName)
+		if err != nil {
+			return err
+		}
+		templateIdField := &core.RelationField{
+			Name:          "template_id",
+			Required:      true,
+			CollectionId:  templates.Id,
+			CascadeDelete: true,
+		}
+		collection.Fields.Add(templateIdField)
+
+		// Add data field holding the configuration values
+		dataField := &core.JSONField{
+			Name:     "data",
+			Required: true,
+		}
+		collection.Fields.Add(dataField)
+
+		// Add is_latest flag (maintained by the server hooks)
+		isLatestField := &core.BoolField{
+			Name: "is_latest",
+		}
+		collection.Fields.Add(isLatestField)
+
+		// Add created timestamp field (auto-populated on create)
+		createdField := &core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		}
+		collection.Fields.Add(createdField)
+
+		// Add updated timestamp field (auto-populated on create and update)
+		updatedField := &core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		}
+		collection.Fields.Add(updatedField)
+
+		// Add indexes for sorting and filtering
+		collection.AddIndex("idx_configurations_created", false, "created", "")
+		collection.AddIndex("idx_configurations_game_id_template_id", false, "game_id, template_id", "")
+
+		// Set access rules (only authenticated users can access)
+		collection.ListRule = types.Pointer("@request.auth.id != ''")
+		collection.ViewRule = types.Pointer("@request.auth.id != ''")
+		collection.CreateRule = types.Pointer("@request.auth.id != ''")
+		collection.UpdateRule = types.Pointer("@request.auth.id != ''")
+		collection.DeleteRule = types.Pointer("@request.auth.id != ''")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// remove configurations collection
+		collection, err := app.FindCollectionByNameOrId(configurationsCollectionName)
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		return app.Delete(collection)
+	})
+}
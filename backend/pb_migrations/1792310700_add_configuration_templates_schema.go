@@ -0,0 +1,36 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(configurationTemplatesCollectionName)
+		if err != nil {
+			return err
+		}
+
+		if collection.Fields.GetByName("schema") != nil {
+			return nil // field already exists
+		}
+
+		// Add schema field holding the JSON Schema of the configurations data
+		schemaField := &core.JSONField{
+			Name: "schema",
+		}
+		collection.Fields.Add(schemaField)
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(configurationTemplatesCollectionName)
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		collection.Fields.RemoveByName("schema")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,122 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// configurations are versioned per (game_id, template_id) pair
+		configurations, err := app.FindCollectionByNameOrId(configurationsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		if configurations.Fields.GetByName("version") == nil {
+			configurations.Fields.Add(&core.NumberField{
+				Name:    "version",
+				OnlyInt: true,
+			})
+			if err := app.Save(configurations); err != nil {
+				return err
+			}
+
+			// number the existing configurations and keep only the newest one as latest
+			records, err := app.FindRecordsByFilter(configurations, "", "created", 0, 0)
+			if err != nil {
+				return err
+			}
+			versions := map[string]*core.Record{}
+			for _, record := range records {
+				key := record.GetString("game_id") + "/" + record.GetString("template_id")
+				record.Set("version", 1)
+				if previous, ok := versions[key]; ok {
+					record.Set("version", previous.GetInt("version")+1)
+					previous.Set("is_latest", false)
+					if err := app.Save(previous); err != nil {
+						return err
+					}
+				}
+				record.Set("is_latest", true)
+				if err := app.Save(record); err != nil {
+					return err
+				}
+				versions[key] = record
+			}
+
+			// there can be only one latest version per lineage
+			configurations.AddIndex("idx_configurations_latest", true, "game_id, template_id", "is_latest = TRUE")
+			if err := app.Save(configurations); err != nil {
+				return err
+			}
+		}
+
+		// advertisement configs keep the id of their first version in lineage_id
+		advertisementConfigs, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		if advertisementConfigs.Fields.GetByName("lineage_id") == nil {
+			advertisementConfigs.Fields.Add(&core.TextField{
+				Name: "lineage_id",
+			})
+			advertisementConfigs.Fields.Add(&core.NumberField{
+				Name:    "version",
+				OnlyInt: true,
+			})
+			advertisementConfigs.Fields.Add(&core.BoolField{
+				Name: "is_latest",
+			})
+			if err := app.Save(advertisementConfigs); err != nil {
+				return err
+			}
+
+			// every existing advertisement config becomes the first version of its own lineage
+			records, err := app.FindAllRecords(advertisementConfigs)
+			if err != nil {
+				return err
+			}
+			for _, record := range records {
+				record.Set("lineage_id", record.Id)
+				record.Set("version", 1)
+				record.Set("is_latest", true)
+				if err := app.Save(record); err != nil {
+					return err
+				}
+			}
+
+			advertisementConfigs.AddIndex("idx_advertisement_configs_lineage_id", false, "lineage_id", "")
+			advertisementConfigs.AddIndex("idx_advertisement_configs_latest", true, "lineage_id", "is_latest = TRUE")
+			if err := app.Save(advertisementConfigs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		configurations, err := app.FindCollectionByNameOrId(configurationsCollectionName)
+		if err == nil {
+			configurations.RemoveIndex("idx_configurations_latest")
+			configurations.Fields.RemoveByName("version")
+			if err := app.Save(configurations); err != nil {
+				return err
+			}
+		}
+
+		advertisementConfigs, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err == nil {
+			advertisementConfigs.RemoveIndex("idx_advertisement_configs_latest")
+			advertisementConfigs.RemoveIndex("idx_advertisement_configs_lineage_id")
+			advertisementConfigs.Fields.RemoveByName("lineage_id")
+			advertisementConfigs.Fields.RemoveByName("version")
+			advertisementConfigs.Fields.RemoveByName("is_latest")
+			if err := app.Save(advertisementConfigs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		if collection.Fields.GetByName("status") != nil {
+			return nil
+		}
+
+		collection.Fields.Add(&core.SelectField{
+			Name:      "status",
+			MaxSelect: 1,
+			Values:    []string{"draft", "in_review", "approved", "published", "archived"},
+		})
+		// the author and approver hold the id of a user or a superuser
+		collection.Fields.Add(&core.TextField{
+			Name: "author",
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "approved_by",
+		})
+		collection.AddIndex("idx_advertisement_configs_status", false, "status", "")
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// the latest versions are already served to the game clients so they stay published
+		_, err = app.DB().
+			Update(collection.Name, dbx.Params{"status": "published"}, dbx.HashExp{"is_latest": true}).
+			Execute()
+		if err != nil {
+			return err
+		}
+		_, err = app.DB().
+			Update(collection.Name, dbx.Params{"status": "archived"}, dbx.HashExp{"is_latest": false}).
+			Execute()
+
+		return err
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return nil
+		}
+
+		collection.RemoveIndex("idx_advertisement_configs_status")
+		collection.Fields.RemoveByName("status")
+		collection.Fields.RemoveByName("author")
+		collection.Fields.RemoveByName("approved_by")
+
+		return app.Save(collection)
+	})
+}
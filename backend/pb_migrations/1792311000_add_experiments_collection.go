@@ -0,0 +1,113 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const experimentsCollectionName = "experiments"
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Check if collection already exists
+		existing, err := app.FindCollectionByNameOrId(experimentsCollectionName)
+		if err == nil && existing != nil {
+			return nil // collection already exists
+		}
+
+		// create experiments collection
+		collection := core.NewBaseCollection(experimentsCollectionName)
+
+		// Add key field matching the experiment_id of the advertisement configs
+		keyField := &core.TextField{
+			Name:     "key",
+			Required: true,
+		}
+		collection.Fields.Add(keyField)
+
+		// Add game_id relation field that references games
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+		gameIdField := &core.RelationField{
+			Name:          "game_id",
+			Required:      true,
+			CollectionId:  games.Id,
+			CascadeDelete: true,
+		}
+		collection.Fields.Add(gameIdField)
+
+		// Add running field, the players are bucketed only while it is set
+		runningField := &core.BoolField{
+			Name: "running",
+		}
+		collection.Fields.Add(runningField)
+
+		// Add created timestamp field (auto-populated on create)
+		createdField := &core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		}
+		collection.Fields.Add(createdField)
+
+		// Add updated timestamp field (auto-populated on create and update)
+		updatedField := &core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		}
+		collection.Fields.Add(updatedField)
+
+		// Add indexes for sorting and filtering
+		collection.AddIndex("idx_experiments_created", false, "created", "")
+		collection.AddIndex("idx_experiments_game_id_key", true, "game_id, key", "")
+
+		// Set access rules (only authenticated users can access)
+		collection.ListRule = types.Pointer("@request.auth.id != ''")
+		collection.ViewRule = types.Pointer("@request.auth.id != ''")
+		collection.CreateRule = types.Pointer("@request.auth.id != ''")
+		collection.UpdateRule = types.Pointer("@request.auth.id != ''")
+		collection.DeleteRule = types.Pointer("@request.auth.id != ''")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Add the traffic weight of the advertisement configs taking part in an experiment
+		advertisementConfigs, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+		if advertisementConfigs.Fields.GetByName("weight") == nil {
+			advertisementConfigs.Fields.Add(&core.NumberField{
+				Name:    "weight",
+				OnlyInt: true,
+				Min:     types.Pointer(0.0),
+			})
+			if err := app.Save(advertisementConfigs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		advertisementConfigs, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err == nil {
+			advertisementConfigs.Fields.RemoveByName("weight")
+			if err := app.Save(advertisementConfigs); err != nil {
+				return err
+			}
+		}
+
+		// remove experiments collection
+		collection, err := app.FindCollectionByNameOrId(experimentsCollectionName)
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,48 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		if collection.Fields.GetByName("targeting") != nil {
+			return nil // fields already exist
+		}
+
+		// Add targeting rules field (validated by the advertisement config targeting hook)
+		collection.Fields.Add(&core.JSONField{
+			Name: "targeting",
+		})
+
+		// Add priority field, the matching configs with a higher priority win
+		collection.Fields.Add(&core.NumberField{
+			Name:    "priority",
+			OnlyInt: true,
+		})
+
+		// Add is_default field marking the fallback config of the games
+		collection.Fields.Add(&core.BoolField{
+			Name: "is_default",
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return nil
+		}
+
+		collection.Fields.RemoveByName("targeting")
+		collection.Fields.RemoveByName("priority")
+		collection.Fields.RemoveByName("is_default")
+
+		return app.Save(collection)
+	})
+}
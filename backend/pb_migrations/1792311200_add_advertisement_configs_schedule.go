@@ -0,0 +1,39 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		if collection.Fields.GetByName("active_from") != nil {
+			return nil // fields already exist
+		}
+
+		// Add the activation window fields (validated by the advertisement config schedule hook)
+		collection.Fields.Add(&core.DateField{
+			Name: "active_from",
+		})
+		collection.Fields.Add(&core.DateField{
+			Name: "active_until",
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return nil
+		}
+
+		collection.Fields.RemoveByName("active_from")
+		collection.Fields.RemoveByName("active_until")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,237 @@
+package pb_migrations
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const placementsCollectionName = "placements"
+
+// defaultPlacements are the placements previously hardcoded in the
+// advertisements_placements placement_id select field.
+var defaultPlacements = []string{
+	"AppReady",
+	"LevelStart",
+	"Button/Undo/Click",
+	"Button/Hint/Click",
+	"Button/Shuffle/Click",
+	"Button/Revive/Click",
+	"LevelProgress_50",
+	"Screen/NoMoreMove/Open",
+	"Screen/LevelComplete/Open",
+}
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Check if collection already exists
+		existing, err := app.FindCollectionByNameOrId(placementsCollectionName)
+		if err == nil && existing != nil {
+			return nil // collection already exists
+		}
+
+		// Keep the existing placement values before the select field is
+		// removed. The relation to the catalog is required, so report all the
+		// placements without a value at once instead of leaving them dangling
+		var rows []struct {
+			Id          string `db:"id"`
+			PlacementId string `db:"placement_id"`
+		}
+		err = app.DB().
+			Select("id", "placement_id").
+			From(advertisementsPlacementsCollectionName).
+			All(&rows)
+		if err != nil {
+			return err
+		}
+		var missing []string
+		for _, row := range rows {
+			if row.PlacementId == "" {
+				missing = append(missing, row.Id)
+			}
+		}
+		if len(missing) > 0 {
+			return errors.New("set the placement_id of the advertisement placements before moving them to the catalog:\n" + strings.Join(missing, "\n"))
+		}
+
+		// create placements collection
+		collection := core.NewBaseCollection(placementsCollectionName)
+
+		// Add name field, the placement key used by the game clients
+		nameField := &core.TextField{
+			Name:     "name",
+			Required: true,
+		}
+		collection.Fields.Add(nameField)
+
+		descriptionField := &core.TextField{
+			Name: "description",
+		}
+		collection.Fields.Add(descriptionField)
+
+		// Add ad_formats field listing the allowed ad format codes (empty allows all of them)
+		adFormatsField := &core.JSONField{
+			Name: "ad_formats",
+		}
+		collection.Fields.Add(adFormatsField)
+
+		// Add optional game_id relation field, the placement is shared by all games when empty
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+		gameIdField := &core.RelationField{
+			Name:          "game_id",
+			CollectionId:  games.Id,
+			CascadeDelete: true,
+		}
+		collection.Fields.Add(gameIdField)
+
+		// Add created timestamp field (auto-populated on create)
+		createdField := &core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		}
+		collection.Fields.Add(createdField)
+
+		// Add updated timestamp field (auto-populated on create and update)
+		updatedField := &core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		}
+		collection.Fields.Add(updatedField)
+
+		// Add indexes for sorting and filtering
+		collection.AddIndex("idx_placements_created", false, "created", "")
+		collection.AddIndex("idx_placements_name_game_id", true, "name, game_id", "")
+
+		// Set access rules (only authenticated users can access)
+		collection.ListRule = types.Pointer("@request.auth.id != ''")
+		collection.ViewRule = types.Pointer("@request.auth.id != ''")
+		collection.CreateRule = types.Pointer("@request.auth.id != ''")
+		collection.UpdateRule = types.Pointer("@request.auth.id != ''")
+		collection.DeleteRule = types.Pointer("@request.auth.id != ''")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		advertisementsPlacements, err := app.FindCollectionByNameOrId(advertisementsPlacementsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		// Create the global catalog entries for the previous select values
+		// and for any other value found in the existing data
+		catalog := map[string]string{}
+		addPlacement := func(name string) error {
+			if _, ok := catalog[name]; ok || name == "" {
+				return nil
+			}
+			record := core.NewRecord(collection)
+			record.Set("name", name)
+			if err := app.Save(record); err != nil {
+				return err
+			}
+			catalog[name] = record.Id
+			return nil
+		}
+		for _, name := range defaultPlacements {
+			if err := addPlacement(name); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			if err := addPlacement(row.PlacementId); err != nil {
+				return err
+			}
+		}
+
+		// Replace the select field with a relation to the catalog
+		advertisementsPlacements.RemoveIndex("idx_advertisement_placements_placement_id")
+		advertisementsPlacements.Fields.RemoveByName("placement_id")
+		if err := app.Save(advertisementsPlacements); err != nil {
+			return err
+		}
+
+		advertisementsPlacements.Fields.Add(&core.RelationField{
+			Name:         "placement_id",
+			Required:     true,
+			CollectionId: collection.Id,
+		})
+		advertisementsPlacements.AddIndex("idx_advertisement_placements_placement_id", false, "placement_id", "")
+		if err := app.Save(advertisementsPlacements); err != nil {
+			return err
+		}
+
+		// Point the existing placements to their catalog entries. The rows are
+		// updated directly so that the versioned placements stay untouched.
+		for _, row := range rows {
+			_, err := app.DB().
+				Update(advertisementsPlacementsCollectionName, dbx.Params{"placement_id": catalog[row.PlacementId]}, dbx.HashExp{"id": row.Id}).
+				Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		advertisementsPlacements, err := app.FindCollectionByNameOrId(advertisementsPlacementsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		// Restore the placement names from the catalog
+		var rows []struct {
+			Id   string `db:"id"`
+			Name string `db:"name"`
+		}
+		err = app.DB().
+			Select("p.id", "c.name").
+			From(advertisementsPlacementsCollectionName+" p").
+			InnerJoin(placementsCollectionName+" c", dbx.NewExp("c.id = p.placement_id")).
+			All(&rows)
+		if err != nil {
+			return err
+		}
+
+		advertisementsPlacements.RemoveIndex("idx_advertisement_placements_placement_id")
+		advertisementsPlacements.Fields.RemoveByName("placement_id")
+		if err := app.Save(advertisementsPlacements); err != nil {
+			return err
+		}
+
+		advertisementsPlacements.Fields.Add(&core.SelectField{
+			Name:     "placement_id",
+			Required: true,
+			Values:   defaultPlacements,
+		})
+		advertisementsPlacements.AddIndex("idx_advertisement_placements_placement_id", false, "placement_id", "")
+		if err := app.Save(advertisementsPlacements); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			_, err := app.DB().
+				Update(advertisementsPlacementsCollectionName, dbx.Params{"placement_id": row.Name}, dbx.HashExp{"id": row.Id}).
+				Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		collection, err := app.FindCollectionByNameOrId(placementsCollectionName)
+		if err != nil {
+			return nil
+		}
+
+		return app.Delete(collection)
+	})
+}
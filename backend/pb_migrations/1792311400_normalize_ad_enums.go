@@ -0,0 +1,90 @@
+package pb_migrations
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// adEnumFields lists the numeric advertisement enum fields with the number
+// of their known values (see the adtypes package) when this migration was
+// written.
+var adEnumFields = []struct {
+	collection string
+	field      string
+	values     int
+}{
+	{advertisementsPlacementsCollectionName, "ad_format", 5},
+	{advertisementsPlacementsCollectionName, "action", 4},
+	{advertisementConfigsCollectionName, "banner_position", 7},
+}
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Report all the records with an unknown code at once instead of
+		// replacing them, the codes being served to the shipped builds
+		var invalid []string
+		for _, enum := range adEnumFields {
+			var rows []struct {
+				Id    string  `db:"id"`
+				Value float64 `db:"value"`
+			}
+			err := app.DB().
+				Select("id", "[["+enum.field+"]] as value").
+				From(enum.collection).
+				All(&rows)
+			if err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				if row.Value != math.Trunc(row.Value) || row.Value < 0 || row.Value >= float64(enum.values) {
+					invalid = append(invalid, fmt.Sprintf("%s %s: %s=%v", enum.collection, row.Id, enum.field, row.Value))
+				}
+			}
+		}
+		if len(invalid) > 0 {
+			return errors.New("fix the unknown advertisement enum codes before restricting them to integers:\n" + strings.Join(invalid, "\n"))
+		}
+
+		for _, enum := range adEnumFields {
+			collection, err := app.FindCollectionByNameOrId(enum.collection)
+			if err != nil {
+				return err
+			}
+
+			field, ok := collection.Fields.GetByName(enum.field).(*core.NumberField)
+			if !ok {
+				continue
+			}
+			field.OnlyInt = true
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		for _, enum := range adEnumFields {
+			collection, err := app.FindCollectionByNameOrId(enum.collection)
+			if err != nil {
+				continue
+			}
+
+			field, ok := collection.Fields.GetByName(enum.field).(*core.NumberField)
+			if !ok {
+				continue
+			}
+			field.OnlyInt = false
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
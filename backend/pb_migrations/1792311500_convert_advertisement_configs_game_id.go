@@ -0,0 +1,209 @@
+package pb_migrations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// maxConfigGames is the max number of games an advertisement config can be
+// assigned to.
+const maxConfigGames = 999
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := collection.Fields.GetByName("game_id").(*core.RelationField); ok {
+			return nil // already converted
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// Map the game identifiers to the games record ids
+		var gameRows []struct {
+			Id     string `db:"id"`
+			GameId string `db:"game_id"`
+		}
+		err = app.DB().
+			Select("id", "game_id").
+			From(games.Name).
+			All(&gameRows)
+		if err != nil {
+			return err
+		}
+		gameIds := make(map[string]string, len(gameRows))
+		for _, row := range gameRows {
+			gameIds[row.GameId] = row.Id
+		}
+
+		// Keep the existing JSON values before the field is removed
+		var rows []struct {
+			Id     string        `db:"id"`
+			GameId types.JSONRaw `db:"game_id"`
+		}
+		err = app.DB().
+			Select("id", "game_id").
+			From(advertisementConfigsCollectionName).
+			All(&rows)
+		if err != nil {
+			return err
+		}
+
+		// Resolve the games of every config first and report all the values
+		// without a matching game at once: the relation is required, so the
+		// configs can't be left without games
+		relations := make(map[string][]string, len(rows))
+		var unmatched []string
+		for _, row := range rows {
+			var values []string
+			if err := json.Unmarshal(row.GameId, &values); err != nil {
+				// a single game identifier instead of a list
+				var value string
+				if err := json.Unmarshal(row.GameId, &value); err == nil && value != "" {
+					values = []string{value}
+				} else if len(row.GameId) > 0 {
+					unmatched = append(unmatched, fmt.Sprintf("%s: invalid game_id %s", row.Id, row.GameId.String()))
+					continue
+				}
+			}
+
+			if len(values) == 0 {
+				unmatched = append(unmatched, fmt.Sprintf("%s: no game_id", row.Id))
+				continue
+			}
+
+			for _, value := range values {
+				gameId, ok := gameIds[value]
+				if !ok {
+					unmatched = append(unmatched, fmt.Sprintf("%s: no game with game_id=%q", row.Id, value))
+					continue
+				}
+				relations[row.Id] = append(relations[row.Id], gameId)
+			}
+		}
+		if len(unmatched) > 0 {
+			return errors.New("create the missing games or fix the game_id of the advertisement configs before converting it to a relation:\n" + strings.Join(unmatched, "\n"))
+		}
+
+		// Replace the JSON field with a relation to the games
+		collection.Fields.RemoveByName("game_id")
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		collection.Fields.Add(&core.RelationField{
+			Name:          "game_id",
+			Required:      true,
+			CollectionId:  games.Id,
+			CascadeDelete: true,
+			MaxSelect:     maxConfigGames,
+		})
+		collection.AddIndex("idx_advertisement_configs_game_id", false, "game_id", "")
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Point the existing configs to their games. The rows are updated
+		// directly so that the versioned configs stay untouched.
+		for id, gameIds := range relations {
+			raw, err := json.Marshal(gameIds)
+			if err != nil {
+				return err
+			}
+			_, err = app.DB().
+				Update(advertisementConfigsCollectionName, dbx.Params{"game_id": string(raw)}, dbx.HashExp{"id": id}).
+				Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		// Restore the game identifiers from the related games
+		var gameRows []struct {
+			Id     string `db:"id"`
+			GameId string `db:"game_id"`
+		}
+		err = app.DB().
+			Select("id", "game_id").
+			From("games").
+			All(&gameRows)
+		if err != nil {
+			return err
+		}
+		gameIds := make(map[string]string, len(gameRows))
+		for _, row := range gameRows {
+			gameIds[row.Id] = row.GameId
+		}
+
+		var rows []struct {
+			Id     string        `db:"id"`
+			GameId types.JSONRaw `db:"game_id"`
+		}
+		err = app.DB().
+			Select("id", "game_id").
+			From(advertisementConfigsCollectionName).
+			All(&rows)
+		if err != nil {
+			return err
+		}
+
+		collection.RemoveIndex("idx_advertisement_configs_game_id")
+		collection.Fields.RemoveByName("game_id")
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		collection.Fields.Add(&core.JSONField{
+			Name:     "game_id",
+			Required: true,
+		})
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			var relations []string
+			_ = json.Unmarshal(row.GameId, &relations)
+
+			values := []string{}
+			for _, relation := range relations {
+				if gameId, ok := gameIds[relation]; ok {
+					values = append(values, gameId)
+				}
+			}
+
+			raw, err := json.Marshal(values)
+			if err != nil {
+				return err
+			}
+			_, err = app.DB().
+				Update(advertisementConfigsCollectionName, dbx.Params{"game_id": string(raw)}, dbx.HashExp{"id": row.Id}).
+				Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
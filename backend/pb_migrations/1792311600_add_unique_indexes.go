@@ -0,0 +1,120 @@
+package pb_migrations
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// uniqueIndexes lists the unique indexes added by this migration.
+//
+// The advertisement configs keep every version of a config, so their names
+// are unique only among the latest versions. They are unique per game since
+// the configs are cloned, imported and promoted from one game to another.
+var uniqueIndexes = []struct {
+	collection string
+	name       string
+	columns    []string
+	where      string
+}{
+	{"games", "idx_games_game_id", []string{"game_id"}, ""},
+	{advertisementsPlacementsCollectionName, "idx_advertisement_placements_advertisement_id_placement_id", []string{"advertisement_id", "placement_id"}, ""},
+	{advertisementConfigsCollectionName, "idx_advertisement_configs_name_experiment_id_game_id", []string{"name", "experiment_id", "game_id"}, "is_latest = TRUE"},
+}
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Report all the existing duplicates at once instead of failing on
+		// the first index creation
+		var duplicates []string
+		for _, index := range uniqueIndexes {
+			found, err := findDuplicates(app, index.collection, index.columns, index.where)
+			if err != nil {
+				return err
+			}
+			duplicates = append(duplicates, found...)
+		}
+		if len(duplicates) > 0 {
+			return errors.New("remove the duplicate records before adding the unique indexes:\n" + strings.Join(duplicates, "\n"))
+		}
+
+		for _, index := range uniqueIndexes {
+			collection, err := app.FindCollectionByNameOrId(index.collection)
+			if err != nil {
+				return err
+			}
+
+			collection.AddIndex(index.name, true, strings.Join(index.columns, ", "), index.where)
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		// Fix the experiment_id index which was indexing the name
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+		collection.RemoveIndex("idx_advertisement_configs_experiment_id")
+		collection.AddIndex("idx_advertisement_configs_experiment_id", false, "experiment_id", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		for _, index := range uniqueIndexes {
+			collection, err := app.FindCollectionByNameOrId(index.collection)
+			if err != nil {
+				return err
+			}
+
+			collection.RemoveIndex(index.name)
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		collection, err := app.FindCollectionByNameOrId(advertisementConfigsCollectionName)
+		if err != nil {
+			return err
+		}
+		collection.RemoveIndex("idx_advertisement_configs_experiment_id")
+		collection.AddIndex("idx_advertisement_configs_experiment_id", false, "name", "")
+
+		return app.Save(collection)
+	})
+}
+
+// findDuplicates describes the values of the given columns shared by
+// several records of the collection.
+func findDuplicates(app core.App, collection string, columns []string, where string) ([]string, error) {
+	selected := append(slices.Clone(columns), "COUNT(*) as total")
+
+	query := app.DB().
+		Select(selected...).
+		From(collection).
+		GroupBy(columns...).
+		Having(dbx.NewExp("COUNT(*) > 1"))
+	if where != "" {
+		query.Where(dbx.NewExp(where))
+	}
+
+	var rows []dbx.NullStringMap
+	if err := query.All(&rows); err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]string, len(rows))
+	for i, row := range rows {
+		values := make([]string, len(columns))
+		for j, column := range columns {
+			values[j] = fmt.Sprintf("%s=%q", column, row[column].String)
+		}
+		duplicates[i] = fmt.Sprintf("%s: %s records with %s", collection, row["total"].String, strings.Join(values, " "))
+	}
+
+	return duplicates, nil
+}
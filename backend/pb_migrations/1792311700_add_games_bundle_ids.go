@@ -0,0 +1,90 @@
+package pb_migrations
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// gamesStoreIdFields lists the optional per-platform store identifiers of the games.
+var gamesStoreIdFields = []string{"ios_bundle_id", "android_package_name"}
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// Add the store identifiers, unique when set
+		for _, name := range gamesStoreIdFields {
+			if collection.Fields.GetByName(name) != nil {
+				continue
+			}
+			collection.Fields.Add(&core.TextField{
+				Name: name,
+			})
+			collection.AddIndex("idx_games_"+name, true, name, name+" != ''")
+		}
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Normalize the existing bundle identifiers. The rows are updated
+		// directly so that their updated date stays untouched.
+		var rows []struct {
+			Id     string `db:"id"`
+			GameId string `db:"game_id"`
+		}
+		err = app.DB().
+			Select("id", "game_id").
+			From(collection.Name).
+			All(&rows)
+		if err != nil {
+			return err
+		}
+
+		existing := make(map[string]bool, len(rows))
+		for _, row := range rows {
+			existing[row.GameId] = true
+		}
+
+		for _, row := range rows {
+			gameId := strings.ToLower(strings.TrimSpace(row.GameId))
+			if gameId == row.GameId {
+				continue
+			}
+			if existing[gameId] {
+				slog.Warn("game_id can't be normalized, another game already uses it", "id", row.Id, "game_id", row.GameId)
+				continue
+			}
+
+			_, err := app.DB().
+				Update(collection.Name, dbx.Params{"game_id": gameId}, dbx.HashExp{"id": row.Id}).
+				Execute()
+			if err != nil {
+				return err
+			}
+			delete(existing, row.GameId)
+			existing[gameId] = true
+		}
+
+		return nil
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		for _, name := range gamesStoreIdFields {
+			collection.RemoveIndex("idx_games_" + name)
+			collection.Fields.RemoveByName(name)
+		}
+
+		return app.Save(collection)
+	})
+}
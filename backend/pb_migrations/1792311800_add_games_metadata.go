@@ -0,0 +1,98 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// gamesMetadataFields lists the game metadata fields added by this migration.
+var gamesMetadataFields = []string{"name", "platforms", "app_store_url", "play_store_url", "icon", "team", "status", "updated"}
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		if collection.Fields.GetByName("status") != nil {
+			return nil // fields already exist
+		}
+
+		// Add display name field
+		collection.Fields.Add(&core.TextField{
+			Name: "name",
+		})
+
+		// Add platforms field listing the platforms the game is released on
+		collection.Fields.Add(&core.SelectField{
+			Name:      "platforms",
+			Values:    []string{"ios", "android"},
+			MaxSelect: 2,
+		})
+
+		// Add the store listing URLs
+		collection.Fields.Add(&core.URLField{
+			Name: "app_store_url",
+		})
+		collection.Fields.Add(&core.URLField{
+			Name: "play_store_url",
+		})
+
+		// Add icon field (raster images only, the SVG files being able to
+		// run scripts when served from the instance origin)
+		collection.Fields.Add(&core.FileField{
+			Name:      "icon",
+			MaxSelect: 1,
+			MaxSize:   1 << 20,
+			MimeTypes: []string{"image/png", "image/jpeg", "image/webp"},
+			Thumbs:    []string{"64x64", "256x256"},
+		})
+
+		// Add owner team field
+		collection.Fields.Add(&core.TextField{
+			Name: "team",
+		})
+
+		// Add status field for the game lifecycle (new games start in development)
+		collection.Fields.Add(&core.SelectField{
+			Name:      "status",
+			Values:    []string{"development", "live", "sunset"},
+			MaxSelect: 1,
+		})
+
+		// Add updated timestamp field (auto-populated on create and update)
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_games_status", false, "status", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// The existing games are already serving configs. The rows are updated
+		// directly so that their updated date matches their creation.
+		_, err = app.DB().
+			Update(collection.Name, dbx.Params{"status": "live", "updated": dbx.NewExp("[[created]]")}, nil).
+			Execute()
+
+		return err
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		collection.RemoveIndex("idx_games_status")
+		for _, name := range gamesMetadataFields {
+			collection.Fields.RemoveByName(name)
+		}
+
+		return app.Save(collection)
+	})
+}
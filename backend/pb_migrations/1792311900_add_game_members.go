@@ -0,0 +1,172 @@
+package pb_migrations
+
+import (
+	"log/slog"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const gameMembersCollectionName = "game_members"
+
+// Access rules restricting the records to the members of their games. The
+// member roles are enforced by the server hooks.
+//
+// The auth check comes first since the games without members would
+// otherwise match the empty id of the guests.
+const (
+	gamesMemberRule                    = "@request.auth.id != '' && game_members_via_game_id.user ?= @request.auth.id"
+	gameMembersMemberRule              = "@request.auth.id != '' && game_id.game_members_via_game_id.user ?= @request.auth.id"
+	advertisementConfigsMemberRule     = "@request.auth.id != '' && game_id.game_members_via_game_id.user ?= @request.auth.id"
+	advertisementsPlacementsMemberRule = "@request.auth.id != '' && advertisement_id.game_id.game_members_via_game_id.user ?= @request.auth.id"
+)
+
+// previousAuthRule is the rule every collection used before the game members.
+const previousAuthRule = "@request.auth.id != ''"
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Check if collection already exists
+		existing, err := app.FindCollectionByNameOrId(gameMembersCollectionName)
+		if err == nil && existing != nil {
+			return nil // collection already exists
+		}
+
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// create game_members collection
+		collection := core.NewBaseCollection(gameMembersCollectionName)
+
+		// Add user relation field that references users
+		collection.Fields.Add(&core.RelationField{
+			Name:          "user",
+			Required:      true,
+			CollectionId:  users.Id,
+			CascadeDelete: true,
+		})
+
+		// Add game_id relation field that references games
+		collection.Fields.Add(&core.RelationField{
+			Name:          "game_id",
+			Required:      true,
+			CollectionId:  games.Id,
+			CascadeDelete: true,
+		})
+
+		// Add role field, each role including the permissions of the previous ones
+		collection.Fields.Add(&core.SelectField{
+			Name:      "role",
+			Required:  true,
+			Values:    []string{"viewer", "editor", "publisher", "owner"},
+			MaxSelect: 1,
+		})
+
+		// Add created timestamp field (auto-populated on create)
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		})
+
+		// Add updated timestamp field (auto-populated on create and update)
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		// Add indexes for sorting and filtering
+		collection.AddIndex("idx_game_members_created", false, "created", "")
+		collection.AddIndex("idx_game_members_user_game_id", true, "user, game_id", "")
+		collection.AddIndex("idx_game_members_game_id", false, "game_id", "")
+
+		// Set access rules (only the members of the game can access)
+		collection.ListRule = types.Pointer(gameMembersMemberRule)
+		collection.ViewRule = types.Pointer(gameMembersMemberRule)
+		collection.CreateRule = types.Pointer(previousAuthRule)
+		collection.UpdateRule = types.Pointer(gameMembersMemberRule)
+		collection.DeleteRule = types.Pointer(gameMembersMemberRule)
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// Keep the read access of the existing users to the existing games,
+		// the write access has to be granted explicitly
+		existingUsers, err := app.FindAllRecords(users)
+		if err != nil {
+			return err
+		}
+		existingGames, err := app.FindAllRecords(games)
+		if err != nil {
+			return err
+		}
+		for _, user := range existingUsers {
+			for _, game := range existingGames {
+				member := core.NewRecord(collection)
+				member.Set("user", user.Id)
+				member.Set("game_id", game.Id)
+				member.Set("role", "viewer")
+				if err := app.Save(member); err != nil {
+					return err
+				}
+			}
+		}
+		if len(existingUsers) > 0 && len(existingGames) > 0 {
+			slog.Info("existing users added as viewers of the existing games", "users", len(existingUsers), "games", len(existingGames))
+		}
+
+		// Restrict the game scoped collections to the game members
+		memberRules := map[string]string{
+			"games":                                gamesMemberRule,
+			advertisementConfigsCollectionName:     advertisementConfigsMemberRule,
+			advertisementsPlacementsCollectionName: advertisementsPlacementsMemberRule,
+		}
+		for name, rule := range memberRules {
+			scoped, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+
+			scoped.ListRule = types.Pointer(rule)
+			scoped.ViewRule = types.Pointer(rule)
+			scoped.UpdateRule = types.Pointer(rule)
+			scoped.DeleteRule = types.Pointer(rule)
+			if err := app.Save(scoped); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		for _, name := range []string{"games", advertisementConfigsCollectionName, advertisementsPlacementsCollectionName} {
+			scoped, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+
+			scoped.ListRule = types.Pointer(previousAuthRule)
+			scoped.ViewRule = types.Pointer(previousAuthRule)
+			scoped.UpdateRule = types.Pointer(previousAuthRule)
+			scoped.DeleteRule = types.Pointer(previousAuthRule)
+			if err := app.Save(scoped); err != nil {
+				return err
+			}
+		}
+
+		collection, err := app.FindCollectionByNameOrId(gameMembersCollectionName)
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		return app.Delete(collection)
+	})
+}
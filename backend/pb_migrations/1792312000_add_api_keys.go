@@ -0,0 +1,175 @@
+package pb_migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const apiKeysCollectionName = "api_keys"
+
+// apiKeyPrefixLength is the number of leading characters of a key kept in
+// clear to identify it.
+const apiKeyPrefixLength = 8
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Check if collection already exists
+		existing, err := app.FindCollectionByNameOrId(apiKeysCollectionName)
+		if err == nil && existing != nil {
+			return nil // collection already exists
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// create api_keys collection
+		collection := core.NewBaseCollection(apiKeysCollectionName)
+
+		// Add game_id relation field that references games
+		collection.Fields.Add(&core.RelationField{
+			Name:          "game_id",
+			Required:      true,
+			CollectionId:  games.Id,
+			CascadeDelete: true,
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name:     "label",
+			Required: true,
+			Max:      100,
+		})
+
+		// Only the SHA-256 hash of the key is stored, the plaintext being
+		// shown once on creation
+		collection.Fields.Add(&core.TextField{
+			Name:     "key_hash",
+			Required: true,
+			Hidden:   true,
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "key_prefix",
+			Max:  apiKeyPrefixLength,
+		})
+
+		// the creator holds the id of a user or a superuser
+		collection.Fields.Add(&core.TextField{
+			Name: "created_by",
+		})
+		collection.Fields.Add(&core.DateField{
+			Name: "last_used_at",
+		})
+		collection.Fields.Add(&core.BoolField{
+			Name: "revoked",
+		})
+		collection.Fields.Add(&core.DateField{
+			Name: "expires_at",
+		})
+
+		// Add created timestamp field (auto-populated on create)
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		})
+
+		// Add updated timestamp field (auto-populated on create and update)
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		collection.AddIndex("idx_api_keys_key_hash", true, "key_hash", "")
+		collection.AddIndex("idx_api_keys_game_id", false, "game_id", "")
+
+		// The members of the game can list its keys, the keys being created,
+		// rotated and revoked through the server routes only
+		collection.ListRule = types.Pointer(advertisementConfigsMemberRule)
+		collection.ViewRule = types.Pointer(advertisementConfigsMemberRule)
+		collection.CreateRule = nil
+		collection.UpdateRule = nil
+		collection.DeleteRule = nil
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// The former game client keys become API keys so that the shipped
+		// builds keep working
+		records, err := app.FindAllRecords(games)
+		if err != nil {
+			return err
+		}
+		converted := 0
+		for _, game := range records {
+			key := game.GetString(gamesClientKeyFieldName)
+			if key == "" {
+				continue
+			}
+
+			hash := sha256.Sum256([]byte(key))
+			apiKey := core.NewRecord(collection)
+			apiKey.Set("game_id", game.Id)
+			apiKey.Set("label", "Client key")
+			apiKey.Set("key_hash", hex.EncodeToString(hash[:]))
+			apiKey.Set("key_prefix", key[:min(len(key), apiKeyPrefixLength)])
+			if err := app.Save(apiKey); err != nil {
+				return err
+			}
+			converted++
+		}
+		if converted > 0 {
+			slog.Info("game client keys converted to API keys", "keys", converted)
+		}
+
+		games.Fields.RemoveByName(gamesClientKeyFieldName)
+
+		return app.Save(games)
+	}, func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// The plaintext of the API keys isn't stored, so the games get new
+		// client keys
+		if games.Fields.GetByName(gamesClientKeyFieldName) == nil {
+			games.Fields.Add(&core.TextField{
+				Name:                gamesClientKeyFieldName,
+				Hidden:              true,
+				Min:                 32,
+				Max:                 32,
+				AutogeneratePattern: "[a-zA-Z0-9]{32}",
+			})
+			if err := app.Save(games); err != nil {
+				return err
+			}
+
+			records, err := app.FindAllRecords(games)
+			if err != nil {
+				return err
+			}
+			for _, record := range records {
+				record.Set(gamesClientKeyFieldName, security.RandomString(32))
+				if err := app.Save(record); err != nil {
+					return err
+				}
+			}
+		}
+
+		collection, err := app.FindCollectionByNameOrId(apiKeysCollectionName)
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		return app.Delete(collection)
+	})
+}
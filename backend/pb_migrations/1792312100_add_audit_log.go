@@ -0,0 +1,94 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+const auditLogCollectionName = "audit_log"
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Check if collection already exists
+		existing, err := app.FindCollectionByNameOrId(auditLogCollectionName)
+		if err == nil && existing != nil {
+			return nil // collection already exists
+		}
+
+		// create audit_log collection
+		collection := core.NewBaseCollection(auditLogCollectionName)
+
+		// the actor holds the id of a user or a superuser (empty for the
+		// guests), its email being kept in case the account is deleted
+		collection.Fields.Add(&core.TextField{
+			Name: "actor",
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "actor_email",
+		})
+
+		collection.Fields.Add(&core.SelectField{
+			Name:      "action",
+			Required:  true,
+			Values:    []string{"create", "update", "delete"},
+			MaxSelect: 1,
+		})
+
+		// The changed record is referenced by its collection name and id
+		// rather than by a relation, so that the entries outlive it
+		collection.Fields.Add(&core.TextField{
+			Name:     "collection",
+			Required: true,
+		})
+		collection.Fields.Add(&core.TextField{
+			Name:     "record_id",
+			Required: true,
+		})
+
+		// Comma separated ids of the games of the record and names of the
+		// changed fields, used to filter the entries
+		collection.Fields.Add(&core.TextField{
+			Name: "game_ids",
+		})
+		collection.Fields.Add(&core.TextField{
+			Name: "fields",
+		})
+
+		// Field-level diff: {"<field>": {"before": <value>, "after": <value>}}
+		collection.Fields.Add(&core.JSONField{
+			Name: "changes",
+		})
+
+		collection.Fields.Add(&core.TextField{
+			Name: "ip",
+		})
+
+		// Add created timestamp field (auto-populated on create)
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		})
+
+		collection.AddIndex("idx_audit_log_created", false, "created", "")
+		collection.AddIndex("idx_audit_log_collection_record_id", false, "collection, record_id", "")
+		collection.AddIndex("idx_audit_log_actor", false, "actor", "")
+
+		// The entries are written by the server only and can't be changed or
+		// deleted; they are read through the /api/audit-log endpoint
+		collection.ListRule = nil
+		collection.ViewRule = nil
+		collection.CreateRule = nil
+		collection.UpdateRule = nil
+		collection.DeleteRule = nil
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(auditLogCollectionName)
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		return app.Delete(collection)
+	})
+}
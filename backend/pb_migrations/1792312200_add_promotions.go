@@ -0,0 +1,78 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const promotionsCollectionName = "promotions"
+
+func init() {
+	m.Register(func(app core.App) error {
+		// Check if collection already exists
+		existing, err := app.FindCollectionByNameOrId(promotionsCollectionName)
+		if err == nil && existing != nil {
+			return nil // collection already exists
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// create promotions collection
+		collection := core.NewBaseCollection(promotionsCollectionName)
+
+		// Add game_id relation field that references games
+		collection.Fields.Add(&core.RelationField{
+			Name:          "game_id",
+			Required:      true,
+			CollectionId:  games.Id,
+			CascadeDelete: true,
+		})
+
+		// the URL of the instance the published configs were promoted from
+		collection.Fields.Add(&core.TextField{
+			Name:     "source",
+			Required: true,
+		})
+
+		// the promoter holds the id of a user or a superuser (empty for the
+		// command line promotions)
+		collection.Fields.Add(&core.TextField{
+			Name: "promoted_by",
+		})
+
+		// The applied changes: [{"action", "collection", "key", "fields", "diff"}]
+		collection.Fields.Add(&core.JSONField{
+			Name: "changes",
+		})
+
+		// Add created timestamp field (auto-populated on create)
+		collection.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+			OnUpdate: false,
+		})
+
+		collection.AddIndex("idx_promotions_game_id", false, "game_id", "")
+
+		// The members of the game can list its promotions, which are recorded
+		// by the server only
+		collection.ListRule = types.Pointer(advertisementConfigsMemberRule)
+		collection.ViewRule = types.Pointer(advertisementConfigsMemberRule)
+		collection.CreateRule = nil
+		collection.UpdateRule = nil
+		collection.DeleteRule = nil
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(promotionsCollectionName)
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		return app.Delete(collection)
+	})
+}
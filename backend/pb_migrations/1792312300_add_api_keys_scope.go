@@ -0,0 +1,51 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(apiKeysCollectionName)
+		if err != nil {
+			return err
+		}
+
+		if collection.Fields.GetByName("scope") != nil {
+			return nil // field already exists
+		}
+
+		// The client keys, shipped in the game builds, only grant access to
+		// the resolved client config while the promotion keys grant access
+		// to all of the published configs of the game
+		collection.Fields.Add(&core.SelectField{
+			Name:      "scope",
+			Values:    []string{"client", "promotion"},
+			MaxSelect: 1,
+		})
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// the existing keys were all meant for the game clients
+		_, err = app.DB().Update(
+			apiKeysCollectionName,
+			dbx.Params{"scope": "client"},
+			dbx.HashExp{"scope": ""},
+		).Execute()
+
+		return err
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId(apiKeysCollectionName)
+		if err != nil {
+			return nil // collection doesn't exist, nothing to remove
+		}
+
+		collection.Fields.RemoveByName("scope")
+
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,66 @@
+package pb_migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Access rules restricting the remaining game scoped collections to the
+// members of their games. The global placements of the catalog, without a
+// game, stay readable by every user but can only be changed by superusers.
+const (
+	gameScopedMemberRule  = "@request.auth.id != '' && game_id.game_members_via_game_id.user ?= @request.auth.id"
+	placementsCatalogRule = "@request.auth.id != '' && (game_id = '' || game_id.game_members_via_game_id.user ?= @request.auth.id)"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// the read and the write rules of each collection
+		rules := map[string]struct{ read, write string }{
+			experimentsCollectionName:            {gameScopedMemberRule, gameScopedMemberRule},
+			configurationTemplatesCollectionName: {gameScopedMemberRule, gameScopedMemberRule},
+			configurationsCollectionName:         {gameScopedMemberRule, gameScopedMemberRule},
+			placementsCollectionName:             {placementsCatalogRule, gameScopedMemberRule},
+		}
+		for name, rule := range rules {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+
+			// the member roles are enforced by the server hooks
+			collection.ListRule = types.Pointer(rule.read)
+			collection.ViewRule = types.Pointer(rule.read)
+			collection.UpdateRule = types.Pointer(rule.write)
+			collection.DeleteRule = types.Pointer(rule.write)
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		for _, name := range []string{
+			experimentsCollectionName,
+			configurationTemplatesCollectionName,
+			configurationsCollectionName,
+			placementsCollectionName,
+		} {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+
+			collection.ListRule = types.Pointer(previousAuthRule)
+			collection.ViewRule = types.Pointer(previousAuthRule)
+			collection.UpdateRule = types.Pointer(previousAuthRule)
+			collection.DeleteRule = types.Pointer(previousAuthRule)
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"config-manager/adtypes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// placementNames returns the catalog name of every advertisement placement
+// indexed by the placement record id.
+func placementNames(app core.App, placements []*core.Record) (map[string]string, error) {
+	if errs := app.ExpandRecords(placements, []string{"placement_id"}, nil); len(errs) > 0 {
+		return nil, fmt.Errorf("failed to expand the placements catalog entries: %v", errs)
+	}
+
+	names := make(map[string]string, len(placements))
+	for _, placement := range placements {
+		if entry := placement.ExpandedOne("placement_id"); entry != nil {
+			names[placement.Id] = entry.GetString("name")
+		}
+	}
+
+	return names, nil
+}
+
+// validatePlacementCatalogEntry makes sure the catalog placement of an
+// advertisement placement is available to the games of its advertisement
+// config and allows its ad format.
+func validatePlacementCatalogEntry(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	entry, err := app.FindRecordById(placementsCollectionName, record.GetString("placement_id"))
+	if err != nil {
+		// let the record validation report the invalid relation
+		return errs, nil
+	}
+
+	adFormats, err := placementAdFormats(entry)
+	if err == nil && len(adFormats) > 0 && !slices.Contains(adFormats, record.GetInt("ad_format")) {
+		errs["ad_format"] = validation.NewError(
+			"validation_ad_format_not_allowed",
+			fmt.Sprintf("not allowed by the placement %s", entry.GetString("name")),
+		)
+	}
+
+	scope := entry.GetString("game_id")
+	if scope == "" {
+		return errs, nil
+	}
+
+	game, err := app.FindRecordById(gamesCollectionName, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := app.FindRecordById(advertisementConfigsCollectionName, record.GetString("advertisement_id"))
+	if err != nil {
+		return errs, nil
+	}
+
+	for _, gameID := range configGameIDs(config) {
+		if gameID != game.Id {
+			errs["placement_id"] = validation.NewError(
+				"validation_placement_scope",
+				fmt.Sprintf("placement %s is available only to the game %s", entry.GetString("name"), game.GetString("game_id")),
+			)
+			break
+		}
+	}
+
+	return errs, nil
+}
+
+// placementAdFormats decodes the ad format codes allowed by a catalog
+// placement, an empty list allowing all of them.
+func placementAdFormats(entry *core.Record) ([]int, error) {
+	raw, _ := entry.Get("ad_formats").(types.JSONRaw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var adFormats []int
+	if err := json.Unmarshal(raw, &adFormats); err != nil {
+		return nil, err
+	}
+
+	return adFormats, nil
+}
+
+// validatePlacementCatalog validates the allowed ad formats of a catalog
+// placement and makes sure its name isn't defined both globally and for a
+// single game, so that the client config placement keys are unambiguous.
+func validatePlacementCatalog(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	adFormats, err := placementAdFormats(record)
+	if err != nil {
+		errs["ad_formats"] = validation.NewError("validation_invalid_ad_formats", "must be a list of ad format codes")
+	}
+	for _, code := range adFormats {
+		if !adtypes.AdFormat(code).IsValid() {
+			errs["ad_formats"] = unknownEnumError(adtypes.AdFormats())
+			break
+		}
+	}
+
+	duplicates, err := app.FindRecordsByFilter(
+		placementsCollectionName,
+		"id != {:id} && name = {:name} && (game_id = '' || {:game} = '' || game_id = {:game})",
+		"",
+		1,
+		0,
+		dbx.Params{
+			"id":   record.Id,
+			"name": record.GetString("name"),
+			"game": record.GetString("game_id"),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(duplicates) > 0 {
+		errs["name"] = validation.NewError(
+			"validation_not_unique",
+			fmt.Sprintf("placement %s already exists", record.GetString("name")),
+		)
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCatalogPlacementID = "testcatalog0001"
+
+func TestPlacementsCatalogMigration(t *testing.T) {
+	app := setupTestApp(t)
+	defer app.Cleanup()
+
+	total, err := app.CountRecords(placementsCollectionName)
+	require.NoError(t, err)
+	assert.EqualValues(t, 9, total, "Previous select values should be converted to global catalog placements")
+
+	collection, err := app.FindCollectionByNameOrId(advertisementsPlacementsCollectionName)
+	require.NoError(t, err)
+	assert.IsType(t, &core.RelationField{}, collection.Fields.GetByName("placement_id"))
+}
+
+func TestPlacementsCatalog(t *testing.T) {
+	seedCatalogPlacement := func(t testing.TB, app core.App, gameRecordID string) {
+		seedClientConfig(t, app)
+		createTestRecord(t, app, gamesCollectionName, map[string]any{
+			"id":      "othergame000001",
+			"game_id": "studio.sun.other",
+		})
+		createTestRecord(t, app, placementsCollectionName, map[string]any{
+			"id":         testCatalogPlacementID,
+			"name":       "Screen/Shop/Open",
+			"ad_formats": []int{2},
+			"game_id":    gameRecordID,
+		})
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "global placement name already exists",
+			Method: http.MethodPost,
+			URL:    "/api/collections/placements/records",
+			Body:   strings.NewReader(`{"name":"AppReady","game_id":"` + testGameRecordID + `"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"Placement AppReady already exists."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "invalid allowed ad formats",
+			Method: http.MethodPost,
+			URL:    "/api/collections/placements/records",
+			Body:   strings.NewReader(`{"name":"Screen/Shop/Open","ad_formats":["banner"]}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"ad_formats":{"code":"validation_invalid_ad_formats"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "ad format not allowed by the placement",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisements_placements/records",
+			Body:   strings.NewReader(`{"advertisement_id":"` + testConfigRecordID + `","placement_id":"` + testCatalogPlacementID + `","ad_format":1}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"ad_format":{"code":"validation_ad_format_not_allowed"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedCatalogPlacement(t, app, testGameRecordID)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "placement of another game",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisements_placements/records",
+			Body:   strings.NewReader(`{"advertisement_id":"` + testConfigRecordID + `","placement_id":"` + testCatalogPlacementID + `","ad_format":2}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"Placement Screen/Shop/Open is available only to the game studio.sun.other."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedCatalogPlacement(t, app, "othergame000001")
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "placement of the config game",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisements_placements/records",
+			Body:   strings.NewReader(`{"advertisement_id":"` + testConfigRecordID + `","placement_id":"` + testCatalogPlacementID + `","ad_format":2}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"placement_id":"` + testCatalogPlacementID + `"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedCatalogPlacement(t, app, testGameRecordID)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
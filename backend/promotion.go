@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// promotionSourceEnv holds the URL of the instance (e.g. staging) the
+	// published configs are promoted from.
+	promotionSourceEnv = "PROMOTION_SOURCE_URL"
+
+	// promotionSourceKeyEnv holds the default promotion API key of the
+	// source game used by the promote command.
+	promotionSourceKeyEnv = "PROMOTION_SOURCE_KEY"
+
+	promotionTimeout = 30 * time.Second
+)
+
+// publishedConfigsDocument returns the published advertisement configs of
+// the game and their placements as an import document, so that they can be
+// applied to another instance.
+func publishedConfigsDocument(app core.App, game *core.Record) (*importDocument, error) {
+	configs, err := app.FindRecordsByFilter(
+		advertisementConfigsCollectionName,
+		"status = {:status} && game_id.id ?= {:game}",
+		"name,experiment_id",
+		0,
+		0,
+		dbx.Params{"status": statusPublished, "game": game.Id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &importDocument{Configs: make([]map[string]any, 0, len(configs))}
+	for _, config := range configs {
+		fields := importFields(config)
+
+		games, err := app.FindRecordsByIds(gamesCollectionName, configGameIDs(config))
+		if err != nil {
+			return nil, err
+		}
+		bundleIDs := make([]string, 0, len(games))
+		for _, game := range games {
+			bundleIDs = append(bundleIDs, game.GetString("game_id"))
+		}
+		slices.Sort(bundleIDs)
+		fields["game_id"] = bundleIDs
+
+		placements, err := app.FindAllRecords(
+			advertisementsPlacementsCollectionName,
+			dbx.HashExp{"advertisement_id": config.Id},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		names, err := placementNames(app, placements)
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(placements, func(a, b *core.Record) int {
+			return strings.Compare(names[a.Id], names[b.Id])
+		})
+
+		items := make([]any, 0, len(placements))
+		for _, placement := range placements {
+			item := importFields(placement)
+			item["placement_id"] = names[placement.Id]
+			items = append(items, item)
+		}
+		fields["placements"] = items
+
+		document.Configs = append(document.Configs, fields)
+	}
+
+	return document, nil
+}
+
+// handlePublishedConfigs serves the published advertisement configs of a
+// game to the instances promoting them. It requires a promotion API key of
+// the game (see requireGameAPIKey), the client keys shipped in the game
+// builds being restricted to the resolved client config.
+func handlePublishedConfigs(e *core.RequestEvent) error {
+	game, ok := e.Get(clientGameContextKey).(*core.Record)
+	if !ok {
+		return e.UnauthorizedError("missing or invalid API key", nil)
+	}
+
+	document, err := publishedConfigsDocument(e.App, game)
+	if err != nil {
+		return e.InternalServerError("failed to load the published configs", err)
+	}
+
+	return e.JSON(http.StatusOK, document)
+}
+
+// fetchPublishedConfigs fetches the published advertisement configs of a
+// game from the source instance, with a promotion API key of the source game.
+func fetchPublishedConfigs(ctx context.Context, source string, gameID string, key string) (*importDocument, error) {
+	endpoint, err := url.JoinPath(source, "api", "promotion", gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(clientKeyHeader, key)
+
+	client := &http.Client{Timeout: promotionTimeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		var body struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(response.Body).Decode(&body)
+		return nil, fmt.Errorf("the source instance responded with %d: %s", response.StatusCode, body.Message)
+	}
+
+	var document importDocument
+	decoder := json.NewDecoder(response.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid response of the source instance: %w", err)
+	}
+
+	return &document, nil
+}
+
+// promoteGame applies the published configs of the source document to the
+// game and records the promotion, when anything changed, in the promotions
+// history of the game. The promoted configs are matched like the imported
+// ones (see importRecords) but published right away, their content being
+// already approved on the source instance.
+//
+// The configs keep only the games known to this instance. A dry run returns
+// the changes without applying them.
+func promoteGame(app core.App, e *core.RequestEvent, game *core.Record, source string, document *importDocument, dryRun bool) ([]importChange, error) {
+	importer := &recordImporter{event: e, publish: true}
+
+	return runImport(app, importer, dryRun, func(importer *recordImporter) error {
+		for _, config := range document.Configs {
+			promoted, err := promotedConfigGames(importer.app, game, config["game_id"])
+			if err != nil {
+				return err
+			}
+
+			config = maps.Clone(config)
+			config["game_id"] = promoted
+			if err := importer.importConfig(config); err != nil {
+				return err
+			}
+		}
+
+		if len(importer.changes) == 0 {
+			return nil
+		}
+
+		collection, err := importer.app.FindCollectionByNameOrId(promotionsCollectionName)
+		if err != nil {
+			return err
+		}
+
+		promotion := core.NewRecord(collection)
+		promotion.Set("game_id", game.Id)
+		promotion.Set("source", source)
+		if author := importer.author(); author != nil {
+			promotion.Set("promoted_by", author.Id)
+		}
+		promotion.Set("changes", importer.changes)
+
+		return importer.app.Save(promotion)
+	})
+}
+
+// promotedConfigGames returns the game_id of the games of a promoted config
+// which exist on this instance, always including the promoted game.
+func promotedConfigGames(app core.App, game *core.Record, value any) ([]any, error) {
+	result := []any{game.GetString("game_id")}
+
+	list, _ := value.([]any)
+	for _, item := range list {
+		bundleID, _ := item.(string)
+
+		other, err := findGameByBundleID(app, bundleID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(result, any(other.GetString("game_id"))) {
+			result = append(result, other.GetString("game_id"))
+		}
+	}
+
+	return result, nil
+}
+
+// handlePromoteGame promotes the published configs of a game from the
+// configured source instance. With dry_run, it only returns the changes.
+func handlePromoteGame(e *core.RequestEvent) error {
+	var body struct {
+		SourceKey string `json:"source_key"`
+		DryRun    bool   `json:"dry_run"`
+	}
+	if err := e.BindBody(&body); err != nil || body.SourceKey == "" {
+		return e.BadRequestError("the promotion API key of the source game is required", err)
+	}
+
+	source := os.Getenv(promotionSourceEnv)
+	if source == "" {
+		return e.BadRequestError("no promotion source instance is configured", nil)
+	}
+
+	game, err := e.App.FindRecordById(gamesCollectionName, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("game not found", nil)
+	}
+
+	if err := requireGameRole(e, rolePublisher, game.Id); err != nil {
+		return err
+	}
+
+	document, err := fetchPublishedConfigs(e.Request.Context(), source, game.GetString("game_id"), body.SourceKey)
+	if err != nil {
+		return e.Error(http.StatusBadGateway, "failed to fetch the published configs of the source instance", err)
+	}
+
+	changes, err := promoteGame(e.App, e, game, source, document, body.DryRun)
+	if err != nil {
+		return e.BadRequestError("failed to promote the configs: "+err.Error(), nil)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"changes": append([]importChange{}, changes...),
+		"dry_run": body.DryRun,
+	})
+}
+
+// newPromoteCommand returns the command promoting the published configs of
+// a game from the source instance. It shows the changes first and asks for
+// a confirmation before applying them.
+func newPromoteCommand(app core.App) *cobra.Command {
+	var source, sourceKey string
+	var dryRun, yes bool
+
+	command := &cobra.Command{
+		Use:          "promote <game_id>",
+		Short:        "Applies the published advertisement configs of a game from another instance",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			source = cmp.Or(source, os.Getenv(promotionSourceEnv))
+			sourceKey = cmp.Or(sourceKey, os.Getenv(promotionSourceKeyEnv))
+			if source == "" || sourceKey == "" {
+				return errors.New("the source instance URL and the promotion API key of the source game are required")
+			}
+
+			game, err := findGameByBundleID(app, args[0])
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("game %q not found", args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			document, err := fetchPublishedConfigs(command.Context(), source, game.GetString("game_id"), sourceKey)
+			if err != nil {
+				return err
+			}
+
+			out := command.OutOrStdout()
+
+			changes, err := promoteGame(app, nil, game, source, document, true)
+			if err != nil {
+				return err
+			}
+			printImportChanges(out, changes)
+			if len(changes) == 0 {
+				fmt.Fprintln(out, "the published configs are already up to date")
+				return nil
+			}
+			if dryRun {
+				fmt.Fprintf(out, "%d changes planned, nothing was written (dry run)\n", len(changes))
+				return nil
+			}
+
+			if !yes {
+				fmt.Fprintf(out, "apply these %d changes? [y/N] ", len(changes))
+				answer, _ := bufio.NewReader(command.InOrStdin()).ReadString('\n')
+				if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+					fmt.Fprintln(out, "promotion cancelled")
+					return nil
+				}
+			}
+
+			changes, err = promoteGame(app, nil, game, source, document, false)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%d changes promoted\n", len(changes))
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&source, "source", "", "the URL of the source instance (defaults to $"+promotionSourceEnv+")")
+	command.Flags().StringVar(&sourceKey, "source-key", "", "a promotion API key of the source game (defaults to $"+promotionSourceKeyEnv+")")
+	command.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes without applying them")
+	command.Flags().BoolVarP(&yes, "yes", "y", false, "apply the changes without asking for a confirmation")
+
+	return command
+}
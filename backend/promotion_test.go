@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveTestApp serves the routes of a test app over HTTP, standing in for
+// another instance.
+func serveTestApp(t *testing.T, app *tests.TestApp) *httptest.Server {
+	router, err := apis.NewRouter(app)
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	err = app.OnServe().Trigger(&core.ServeEvent{App: app, Router: router}, func(e *core.ServeEvent) error {
+		mux, err := e.Router.BuildMux()
+		if err != nil {
+			return err
+		}
+		server = httptest.NewServer(mux)
+		return nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// testPromotionKey is the plaintext promotion API key of the test game.
+const testPromotionKey = "cm_promotionkeypromotionkeypromotionkey"
+
+// seedPromotionKey creates a promotion API key of the test game.
+func seedPromotionKey(t testing.TB, app core.App) {
+	createTestRecord(t, app, apiKeysCollectionName, map[string]any{
+		"game_id":  testGameRecordID,
+		"label":    "Production instance",
+		"scope":    apiKeyScopePromotion,
+		"key_hash": hashAPIKey(testPromotionKey),
+	})
+}
+
+// seedPromotionSource serves a source instance with the seeded client config.
+func seedPromotionSource(t *testing.T) (*tests.TestApp, *httptest.Server) {
+	source := setupTestApp(t)
+	t.Cleanup(source.Cleanup)
+	seedClientConfig(t, source)
+	seedPromotionKey(t, source)
+
+	return source, serveTestApp(t, source)
+}
+
+// seedPromotionTarget creates the game promoted to the target instance.
+func seedPromotionTarget(t testing.TB, app core.App) *core.Record {
+	return createTestRecord(t, app, gamesCollectionName, map[string]any{
+		"id":      testGameRecordID,
+		"game_id": testGameID,
+	})
+}
+
+func TestPublishedConfigsEndpoint(t *testing.T) {
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedClientConfig(t, app)
+		seedPromotionKey(t, app)
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "missing API key",
+			Method:          http.MethodGet,
+			URL:             "/api/promotion/" + testGameID,
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:            "client key",
+			Method:          http.MethodGet,
+			URL:             "/api/promotion/" + testGameID,
+			Headers:         map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The promotion scope is required."},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:           "published configs",
+			Method:         http.MethodGet,
+			URL:            "/api/promotion/" + testGameID,
+			Headers:        map[string]string{clientKeyHeader: testPromotionKey},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"name":"default"`,
+				`"game_id":["` + testGameID + `"]`,
+				`"placement_id":"AppReady"`,
+				`"placement_id":"Button/Hint/Click"`,
+			},
+			NotExpectedContent: []string{`"status"`, `"lineage_id"`, `"advertisement_id"`},
+			BeforeTestFunc:     seed,
+			TestAppFactory:     setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestPromoteGame(t *testing.T) {
+	source, server := seedPromotionSource(t)
+
+	target := setupTestApp(t)
+	defer target.Cleanup()
+	game := seedPromotionTarget(t, target)
+
+	promote := func(t *testing.T, dryRun bool) []string {
+		document, err := fetchPublishedConfigs(context.Background(), server.URL, testGameID, testPromotionKey)
+		require.NoError(t, err)
+
+		changes, err := promoteGame(target, nil, game, server.URL, document, dryRun)
+		require.NoError(t, err)
+
+		result := make([]string, 0, len(changes))
+		for _, change := range changes {
+			result = append(result, change.String())
+		}
+		return result
+	}
+
+	findPromotions := func(t *testing.T) []*core.Record {
+		promotions, err := target.FindAllRecords(promotionsCollectionName)
+		require.NoError(t, err)
+		return promotions
+	}
+
+	t.Run("create", func(t *testing.T) {
+		expected := []string{
+			"create advertisement_configs default [control]",
+			"create advertisements_placements default [control] AppReady",
+			"create advertisements_placements default [control] Button/Hint/Click",
+		}
+
+		assert.Equal(t, expected, promote(t, true))
+		configs, err := target.FindAllRecords(advertisementConfigsCollectionName)
+		require.NoError(t, err)
+		assert.Empty(t, configs, "A dry run should not write anything")
+		assert.Empty(t, findPromotions(t))
+
+		assert.Equal(t, expected, promote(t, false))
+
+		config, err := target.FindFirstRecordByData(advertisementConfigsCollectionName, "name", "default")
+		require.NoError(t, err)
+		assert.Equal(t, statusPublished, config.GetString("status"), "The promoted configs should be published")
+		assert.Equal(t, 60, config.GetInt("banner_refresh_rate"))
+
+		placements, err := target.FindAllRecords(advertisementsPlacementsCollectionName, dbx.HashExp{"advertisement_id": config.Id})
+		require.NoError(t, err)
+		assert.Len(t, placements, 2)
+
+		promotions := findPromotions(t)
+		require.Len(t, promotions, 1)
+		assert.Equal(t, game.Id, promotions[0].GetString("game_id"))
+		assert.Equal(t, server.URL, promotions[0].GetString("source"))
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		assert.Empty(t, promote(t, false))
+		assert.Len(t, findPromotions(t), 1, "Promotions without changes should not be recorded")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		config, err := source.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+		require.NoError(t, err)
+		config.Set("banner_refresh_rate", 90)
+		require.NoError(t, source.Save(config))
+
+		document, err := fetchPublishedConfigs(context.Background(), server.URL, testGameID, testPromotionKey)
+		require.NoError(t, err)
+		changes, err := promoteGame(target, nil, game, server.URL, document, false)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, "update advertisement_configs default [control] (banner_refresh_rate)", changes[0].String())
+		assert.Equal(t, auditChange{Before: 60.0, After: 90.0}, changes[0].Diff["banner_refresh_rate"])
+
+		versions, err := target.FindRecordsByFilter(advertisementConfigsCollectionName, "name = 'default'", "version", 0, 0)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, statusArchived, versions[0].GetString("status"), "The previous version should be archived")
+		assert.Equal(t, statusPublished, versions[1].GetString("status"))
+		assert.Len(t, findPromotions(t), 2)
+	})
+
+	t.Run("invalid source key", func(t *testing.T) {
+		_, err := fetchPublishedConfigs(context.Background(), server.URL, testGameID, "invalid")
+		assert.ErrorContains(t, err, "responded with 401")
+	})
+
+	t.Run("client key", func(t *testing.T) {
+		_, err := fetchPublishedConfigs(context.Background(), server.URL, testGameID, testClientKey)
+		assert.ErrorContains(t, err, "responded with 403")
+	})
+}
+
+func TestPromoteGameRoute(t *testing.T) {
+	_, server := seedPromotionSource(t)
+	t.Setenv(promotionSourceEnv, server.URL)
+
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedPromotionTarget(t, app)
+	}
+	body := func(dryRun bool) *strings.Reader {
+		if dryRun {
+			return strings.NewReader(`{"source_key":"` + testPromotionKey + `","dry_run":true}`)
+		}
+		return strings.NewReader(`{"source_key":"` + testPromotionKey + `"}`)
+	}
+	url := "/api/games/" + testGameRecordID + "/promote"
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            body(false),
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:            "missing source key",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{}`),
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"The promotion API key of the source game is required."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "no source instance",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            body(false),
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"No promotion source instance is configured."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				t.Setenv(promotionSourceEnv, "")
+				seed(t, app, e)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "editor",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            body(true),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				seedGameMember(t, app, roleEditor)
+				authenticateAsUser(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "invalid source key",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"source_key":"invalid"}`),
+			ExpectedStatus:  502,
+			ExpectedContent: []string{"Failed to fetch the published configs of the source instance."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "publisher dry run",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            body(true),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"dry_run":true`, `"action":"create"`, `"key":"default [control] AppReady"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				seedGameMember(t, app, rolePublisher)
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				configs, err := app.FindAllRecords(advertisementConfigsCollectionName)
+				require.NoError(t, err)
+				assert.Empty(t, configs)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "admin",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            body(false),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"dry_run":false`, `"collection":"advertisement_configs"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seed(t, app, e)
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				admin, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, adminEmail)
+				require.NoError(t, err)
+
+				promotions, err := app.FindAllRecords(promotionsCollectionName)
+				require.NoError(t, err)
+				require.Len(t, promotions, 1)
+				assert.Equal(t, admin.Id, promotions[0].GetString("promoted_by"))
+
+				entries := findAuditEntries(t, app, advertisementConfigsCollectionName)
+				require.Len(t, entries, 1)
+				assert.Equal(t, admin.Id, entries[0].GetString("actor"))
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// isScheduled reports whether the advertisement config has an activation
+// window. Scheduled configs take precedence over the unscheduled ones while
+// their window is active.
+func isScheduled(config *core.Record) bool {
+	return !config.GetDateTime("active_from").IsZero() || !config.GetDateTime("active_until").IsZero()
+}
+
+// isActiveAt reports whether the activation window of the advertisement
+// config contains the given time. The window start is inclusive and its end
+// exclusive, a missing bound meaning the window is open on that side.
+func isActiveAt(config *core.Record, at time.Time) bool {
+	from := config.GetDateTime("active_from")
+	if !from.IsZero() && at.Before(from.Time()) {
+		return false
+	}
+
+	until := config.GetDateTime("active_until")
+	if !until.IsZero() && !at.Before(until.Time()) {
+		return false
+	}
+
+	return true
+}
+
+// windowsOverlap reports whether the activation windows of the two
+// advertisement configs have any time in common.
+func windowsOverlap(a *core.Record, b *core.Record) bool {
+	// a starts before b ends and b starts before a ends
+	startsBeforeEnd := func(start *core.Record, end *core.Record) bool {
+		from := start.GetDateTime("active_from")
+		until := end.GetDateTime("active_until")
+		return from.IsZero() || until.IsZero() || from.Time().Before(until.Time())
+	}
+
+	return startsBeforeEnd(a, b) && startsBeforeEnd(b, a)
+}
+
+// validateAdConfigSchedule validates the activation window of an
+// advertisement config and makes sure it doesn't overlap with the window of
+// another scheduled config of the same game and experiment, unless the
+// experiment is running in that game: its variants are then served side by
+// side to different players.
+func validateAdConfigSchedule(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+	if !isScheduled(record) {
+		return errs, nil
+	}
+
+	from := record.GetDateTime("active_from")
+	until := record.GetDateTime("active_until")
+	if !from.IsZero() && !until.IsZero() && !from.Time().Before(until.Time()) {
+		errs["active_until"] = validation.NewError("validation_invalid_range", "must be after active_from")
+		return errs, nil
+	}
+
+	// the versions of the same lineage replace each other so only the
+	// other lineages versions that are (or are going to be) served count
+	experimentKey := record.GetString("experiment_id")
+	others, err := app.FindRecordsByFilter(
+		advertisementConfigsCollectionName,
+		"lineage_id != {:lineage} && experiment_id = {:experiment} && (is_latest = true || status = {:published}) && (active_from != '' || active_until != '')",
+		"",
+		0,
+		0,
+		dbx.Params{
+			"lineage":    record.GetString("lineage_id"),
+			"experiment": experimentKey,
+			"published":  statusPublished,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	gameIDs := configGameIDs(record)
+	for _, other := range others {
+		if !windowsOverlap(record, other) {
+			continue
+		}
+
+		for _, gameID := range configGameIDs(other) {
+			if !slices.Contains(gameIDs, gameID) {
+				continue
+			}
+
+			running, err := app.CountRecords(
+				experimentsCollectionName,
+				dbx.HashExp{"game_id": gameID, "key": experimentKey, "running": true},
+			)
+			if err != nil {
+				return nil, err
+			}
+			if running > 0 {
+				continue
+			}
+
+			errs["active_from"] = validation.NewError(
+				"validation_window_overlap",
+				fmt.Sprintf("overlaps with the activation window of the advertisement config %q (%s)", other.GetString("name"), other.Id),
+			)
+			return errs, nil
+		}
+	}
+
+	return errs, nil
+}
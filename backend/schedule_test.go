@@ -0,0 +1,179 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivationWindows(t *testing.T) {
+	collection := core.NewBaseCollection(advertisementConfigsCollectionName)
+	collection.Fields.Add(
+		&core.DateField{Name: "active_from"},
+		&core.DateField{Name: "active_until"},
+	)
+	newConfig := func(from string, until string) *core.Record {
+		record := core.NewRecord(collection)
+		record.Set("active_from", from)
+		record.Set("active_until", until)
+		return record
+	}
+
+	christmas := newConfig("2026-12-24 00:00:00.000Z", "2026-12-27 00:00:00.000Z")
+	newYear := newConfig("2026-12-27 00:00:00.000Z", "2027-01-02 00:00:00.000Z")
+	holidays := newConfig("2026-12-20 00:00:00.000Z", "")
+	always := newConfig("", "")
+
+	t.Run("active", func(t *testing.T) {
+		assert.True(t, isActiveAt(christmas, time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)))
+		assert.False(t, isActiveAt(christmas, time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC)), "Window end should be exclusive")
+		assert.True(t, isActiveAt(holidays, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, isActiveAt(always, time.Now()))
+		assert.False(t, isScheduled(always))
+	})
+
+	t.Run("overlap", func(t *testing.T) {
+		assert.False(t, windowsOverlap(christmas, newYear), "Adjacent windows should not overlap")
+		assert.True(t, windowsOverlap(christmas, holidays))
+		assert.True(t, windowsOverlap(newYear, holidays))
+	})
+}
+
+func TestAdConfigSchedule(t *testing.T) {
+	seedScheduled := func(t testing.TB, app core.App, from time.Time, until time.Time) {
+		seedClientConfig(t, app)
+		createTestRecord(t, app, advertisementConfigsCollectionName, map[string]any{
+			"id":                  testVariantConfigRecordID,
+			"lineage_id":          testVariantConfigRecordID,
+			"version":             1,
+			"is_latest":           true,
+			"status":              statusPublished,
+			"name":                "holidays",
+			"experiment_id":       "control",
+			"game_id":             []string{testGameRecordID},
+			"banner_refresh_rate": 30,
+			"active_from":         from,
+			"active_until":        until,
+		})
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "window ending before it starts",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body: strings.NewReader(`{"name":"new","experiment_id":"control","game_id":["` + testGameRecordID + `"],
+				"active_from":"2026-12-27 00:00:00.000Z","active_until":"2026-12-24 00:00:00.000Z"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"active_until":{"code":"validation_invalid_range"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "overlapping window of the same game and experiment",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body: strings.NewReader(`{"name":"new","experiment_id":"control","game_id":["` + testGameRecordID + `"],
+				"active_from":"2026-12-26 00:00:00.000Z"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"active_from":{"code":"validation_window_overlap"`, `Overlaps with the activation window of the advertisement config \"holidays\"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedScheduled(t, app, time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC), time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC))
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "overlapping window of a variant of the running experiment",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body: strings.NewReader(`{"name":"holidays-aggressive","experiment_id":"control","game_id":["` + testGameRecordID + `"],
+				"active_from":"2026-12-24 00:00:00.000Z","active_until":"2026-12-27 00:00:00.000Z"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"name":"holidays-aggressive"`, `"active_from":"2026-12-24 00:00:00.000Z"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedScheduled(t, app, time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC), time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC))
+				createTestRecord(t, app, experimentsCollectionName, map[string]any{
+					"key":     "control",
+					"game_id": testGameRecordID,
+					"running": true,
+				})
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "window of another experiment",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body: strings.NewReader(`{"name":"new","experiment_id":"other","game_id":["` + testGameRecordID + `"],
+				"active_from":"2026-12-26 00:00:00.000Z"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"active_from":"2026-12-26 00:00:00.000Z"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedScheduled(t, app, time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC), time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC))
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "active scheduled config is served",
+			Method:         http.MethodGet,
+			URL:            "/api/client-config/" + testGameID,
+			Headers:        map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"config_id":"` + testVariantConfigRecordID + `"`,
+				`"refresh_rate":30`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedScheduled(t, app, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+				// the unscheduled config is more recent but the scheduled one takes precedence
+				config, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+				require.NoError(t, err)
+				config.Set("banner_refresh_rate", 45)
+				require.NoError(t, app.Save(config))
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "expired scheduled config reverts",
+			Method:         http.MethodGet,
+			URL:            "/api/client-config/" + testGameID,
+			Headers:        map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"config_id":"` + testConfigRecordID + `"`,
+				`"refresh_rate":60`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedScheduled(t, app, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
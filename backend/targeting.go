@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// targetingPlatforms lists the supported targeting platforms.
+var targetingPlatforms = []string{"ios", "android"}
+
+var (
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	appVersionPattern  = regexp.MustCompile(`^\d+(\.\d+)*$`)
+)
+
+// targetingRules is the audience an advertisement config is served to.
+//
+// Every set criterion must match the client, so an empty rule set matches
+// everyone.
+type targetingRules struct {
+	Platforms     []string `json:"platforms,omitempty"`
+	Countries     []string `json:"countries,omitempty"`
+	MinAppVersion string   `json:"min_app_version,omitempty"`
+	MaxAppVersion string   `json:"max_app_version,omitempty"`
+	MinLevel      *int     `json:"min_level,omitempty"`
+	MaxLevel      *int     `json:"max_level,omitempty"`
+}
+
+// clientContext describes the game client requesting its config.
+type clientContext struct {
+	PlayerID   string
+	Platform   string
+	Country    string
+	AppVersion string
+	Level      *int
+	Now        time.Time
+}
+
+// newClientContext reads the client context from the client config query parameters.
+func newClientContext(query url.Values) clientContext {
+	client := clientContext{
+		PlayerID:   query.Get(playerIDParam),
+		Platform:   strings.ToLower(query.Get("platform")),
+		Country:    strings.ToUpper(query.Get("country")),
+		AppVersion: query.Get("app_version"),
+		Now:        time.Now(),
+	}
+
+	if level, err := strconv.Atoi(query.Get("level")); err == nil {
+		client.Level = &level
+	}
+
+	return client
+}
+
+// parseTargetingRules decodes the targeting rules of an advertisement config.
+func parseTargetingRules(config *core.Record) (targetingRules, error) {
+	var rules targetingRules
+
+	raw, _ := config.Get("targeting").(types.JSONRaw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return rules, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return rules, err
+	}
+
+	return rules, nil
+}
+
+// Validate implements [validation.Validatable].
+func (rules targetingRules) Validate() error {
+	return validation.ValidateStruct(&rules,
+		validation.Field(&rules.Platforms, validation.Each(validation.In(toAny(targetingPlatforms)...))),
+		validation.Field(&rules.Countries, validation.Each(validation.Match(countryCodePattern))),
+		validation.Field(&rules.MinAppVersion, validation.Match(appVersionPattern)),
+		validation.Field(
+			&rules.MaxAppVersion,
+			validation.Match(appVersionPattern),
+			validation.By(func(any) error {
+				if rules.MinAppVersion != "" && rules.MaxAppVersion != "" &&
+					compareAppVersions(rules.MinAppVersion, rules.MaxAppVersion) > 0 {
+					return validation.NewError("validation_invalid_range", "must not be lower than min_app_version")
+				}
+				return nil
+			}),
+		),
+		validation.Field(&rules.MinLevel, validation.Min(0)),
+		validation.Field(
+			&rules.MaxLevel,
+			validation.Min(0),
+			validation.By(func(any) error {
+				if rules.MinLevel != nil && rules.MaxLevel != nil && *rules.MinLevel > *rules.MaxLevel {
+					return validation.NewError("validation_invalid_range", "must not be lower than min_level")
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+// specificity returns the number of criteria set in the rules.
+func (rules targetingRules) specificity() int {
+	total := 0
+	for _, set := range []bool{
+		len(rules.Platforms) > 0,
+		len(rules.Countries) > 0,
+		rules.MinAppVersion != "",
+		rules.MaxAppVersion != "",
+		rules.MinLevel != nil,
+		rules.MaxLevel != nil,
+	} {
+		if set {
+			total++
+		}
+	}
+
+	return total
+}
+
+// matches reports whether the client is part of the targeted audience.
+// Criteria the client didn't provide a value for never match.
+func (rules targetingRules) matches(client clientContext) bool {
+	if len(rules.Platforms) > 0 && !slices.Contains(rules.Platforms, client.Platform) {
+		return false
+	}
+
+	if len(rules.Countries) > 0 && !slices.Contains(rules.Countries, client.Country) {
+		return false
+	}
+
+	if rules.MinAppVersion != "" || rules.MaxAppVersion != "" {
+		if !appVersionPattern.MatchString(client.AppVersion) {
+			return false
+		}
+		if rules.MinAppVersion != "" && compareAppVersions(client.AppVersion, rules.MinAppVersion) < 0 {
+			return false
+		}
+		if rules.MaxAppVersion != "" && compareAppVersions(client.AppVersion, rules.MaxAppVersion) > 0 {
+			return false
+		}
+	}
+
+	if rules.MinLevel != nil || rules.MaxLevel != nil {
+		if client.Level == nil {
+			return false
+		}
+		if rules.MinLevel != nil && *client.Level < *rules.MinLevel {
+			return false
+		}
+		if rules.MaxLevel != nil && *client.Level > *rules.MaxLevel {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareAppVersions compares two dotted numeric versions, the missing
+// components being treated as zeros (so "1.2" equals "1.2.0").
+func compareAppVersions(a string, b string) int {
+	partsA := strings.Split(a, ".")
+	partsB := strings.Split(b, ".")
+
+	for i := range max(len(partsA), len(partsB)) {
+		var numberA, numberB int
+		if i < len(partsA) {
+			numberA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numberB, _ = strconv.Atoi(partsB[i])
+		}
+		if result := cmp.Compare(numberA, numberB); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+// selectTargetedConfigs returns the configs active at the client time whose
+// targeting matches the client, ordered by precedence: the scheduled configs
+// first, then the highest priority, then the most specific targeting, then
+// the most recently updated config and finally the config id.
+//
+// The default configs are used only when no other config matches.
+func selectTargetedConfigs(configs []*core.Record, client clientContext) []*core.Record {
+	type candidate struct {
+		config      *core.Record
+		specificity int
+	}
+
+	var matching, defaults []candidate
+	for _, config := range configs {
+		if !isActiveAt(config, client.Now) {
+			continue
+		}
+
+		rules, err := parseTargetingRules(config)
+		if err != nil {
+			continue
+		}
+
+		if config.GetBool("is_default") {
+			defaults = append(defaults, candidate{config, rules.specificity()})
+			continue
+		}
+
+		if rules.matches(client) {
+			matching = append(matching, candidate{config, rules.specificity()})
+		}
+	}
+	if len(matching) == 0 {
+		matching = defaults
+	}
+
+	slices.SortStableFunc(matching, func(a, b candidate) int {
+		return cmp.Or(
+			compareBool(isScheduled(b.config), isScheduled(a.config)),
+			cmp.Compare(b.config.GetInt("priority"), a.config.GetInt("priority")),
+			cmp.Compare(b.specificity, a.specificity),
+			b.config.GetDateTime("updated").Compare(a.config.GetDateTime("updated")),
+			strings.Compare(a.config.Id, b.config.Id),
+		)
+	})
+
+	result := make([]*core.Record, len(matching))
+	for i, candidate := range matching {
+		result[i] = candidate.config
+	}
+
+	return result
+}
+
+// validateAdConfigTargeting validates the targeting rules of an advertisement
+// config and makes sure there is at most a single default config per game.
+func validateAdConfigTargeting(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	rules, err := parseTargetingRules(record)
+	if err != nil {
+		errs["targeting"] = validation.NewError("validation_invalid_targeting", err.Error())
+	} else if err := rules.Validate(); err != nil {
+		errs["targeting"] = err
+	}
+
+	if !record.GetBool("is_default") {
+		return errs, nil
+	}
+
+	// the other versions of the same lineage are allowed to be defaults as well
+	defaults, err := app.FindAllRecords(
+		advertisementConfigsCollectionName,
+		dbx.HashExp{"is_default": true, "is_latest": true},
+		dbx.Not(dbx.HashExp{"lineage_id": record.GetString("lineage_id")}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	gameIDs := configGameIDs(record)
+	for _, other := range defaults {
+		for _, gameID := range configGameIDs(other) {
+			if !slices.Contains(gameIDs, gameID) {
+				continue
+			}
+
+			game, err := app.FindRecordById(gamesCollectionName, gameID)
+			if err != nil {
+				return nil, err
+			}
+			errs["is_default"] = validation.NewError(
+				"validation_default_exists",
+				fmt.Sprintf("game %s already has a default advertisement config", game.GetString("game_id")),
+			)
+			return errs, nil
+		}
+	}
+
+	return errs, nil
+}
+
+func compareBool(a bool, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func toAny[T any](values []T) []any {
+	result := make([]any, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+
+	return result
+}
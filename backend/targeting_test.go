@@ -0,0 +1,173 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareAppVersions(t *testing.T) {
+	assert.Equal(t, 0, compareAppVersions("1.2", "1.2.0"))
+	assert.Equal(t, -1, compareAppVersions("1.2.9", "1.2.10"))
+	assert.Equal(t, 1, compareAppVersions("2.0", "1.99.99"))
+}
+
+func TestTargetingRulesMatches(t *testing.T) {
+	minLevel, level := 5, 7
+	rules := targetingRules{
+		Platforms:     []string{"ios"},
+		Countries:     []string{"US", "CA"},
+		MinAppVersion: "2.1",
+		MinLevel:      &minLevel,
+	}
+
+	client := clientContext{Platform: "ios", Country: "US", AppVersion: "2.1.3", Level: &level}
+	assert.True(t, rules.matches(client))
+
+	android := client
+	android.Platform = "android"
+	assert.False(t, rules.matches(android))
+
+	oldBuild := client
+	oldBuild.AppVersion = "2.0.9"
+	assert.False(t, rules.matches(oldBuild))
+
+	unknownLevel := client
+	unknownLevel.Level = nil
+	assert.False(t, rules.matches(unknownLevel), "Criteria without client value should not match")
+
+	assert.True(t, targetingRules{}.matches(clientContext{}), "Empty rules should match everyone")
+}
+
+func TestSelectTargetedConfigs(t *testing.T) {
+	app, err := tests.NewTestApp()
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection(advertisementConfigsCollectionName)
+	collection.Fields.Add(
+		&core.JSONField{Name: "targeting"},
+		&core.NumberField{Name: "priority", OnlyInt: true},
+		&core.BoolField{Name: "is_default"},
+	)
+	newConfig := func(id string, targeting string, priority int, isDefault bool) *core.Record {
+		record := core.NewRecord(collection)
+		record.Id = id
+		if targeting != "" {
+			record.Set("targeting", targeting)
+		}
+		record.Set("priority", priority)
+		record.Set("is_default", isDefault)
+		return record
+	}
+
+	fallback := newConfig("fallback", "", 0, true)
+	everyone := newConfig("everyone", "", 0, false)
+	ios := newConfig("ios", `{"platforms":["ios"]}`, 0, false)
+	iosUS := newConfig("ios_us", `{"platforms":["ios"],"countries":["US"]}`, 0, false)
+	boosted := newConfig("boosted", `{"countries":["US"]}`, 10, false)
+	configs := []*core.Record{fallback, everyone, ios, iosUS, boosted}
+
+	selected := selectTargetedConfigs(configs, clientContext{Platform: "ios", Country: "US"})
+	require.Len(t, selected, 4)
+	assert.Equal(t, "boosted", selected[0].Id, "Higher priority should win")
+	assert.Equal(t, "ios_us", selected[1].Id, "More specific targeting should win")
+	assert.Equal(t, "ios", selected[2].Id)
+	assert.Equal(t, "everyone", selected[3].Id)
+
+	selected = selectTargetedConfigs([]*core.Record{fallback, ios}, clientContext{Platform: "android"})
+	require.Len(t, selected, 1)
+	assert.Equal(t, "fallback", selected[0].Id, "Default config should be used when nothing matches")
+}
+
+func TestAdConfigTargeting(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "invalid targeting rules",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body: strings.NewReader(`{"name":"new","experiment_id":"control","game_id":["` + testGameRecordID + `"],
+				"targeting":{"platforms":["windows"],"min_level":10,"max_level":5}}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"platforms":{"0":{"code":"validation_in_invalid"`,
+				`"max_level":{"code":"validation_invalid_range"`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "unknown targeting criteria",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body:   strings.NewReader(`{"name":"new","experiment_id":"control","game_id":["` + testGameRecordID + `"],"targeting":{"os":"ios"}}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"targeting":{"code":"validation_invalid_targeting"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "second default config of a game",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body:   strings.NewReader(`{"name":"new","experiment_id":"control","game_id":["` + testGameRecordID + `"],"is_default":true}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"Game studio.sun.rpg already has a default advertisement config."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				config := seedClientConfig(t, app)
+				config.Set("is_default", true)
+				require.NoError(t, app.Save(config))
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "client gets the config targeting its platform",
+			Method:         http.MethodGet,
+			URL:            "/api/client-config/" + testGameID + "?platform=ios&app_version=2.4.0",
+			Headers:        map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"config_id":"` + testVariantConfigRecordID + `"`,
+				`"name":"ios"`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				createTestRecord(t, app, advertisementConfigsCollectionName, map[string]any{
+					"id":            testVariantConfigRecordID,
+					"lineage_id":    testVariantConfigRecordID,
+					"version":       1,
+					"is_latest":     true,
+					"status":        statusPublished,
+					"name":          "ios",
+					"experiment_id": "control",
+					"game_id":       []string{testGameRecordID},
+					"targeting":     map[string]any{"platforms": []string{"ios"}, "min_app_version": "2.0"},
+				})
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"config-manager/adtypes"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Allowed banner refresh rates in seconds, 0 disabling the refresh.
+const (
+	minBannerRefreshRate = 30
+	maxBannerRefreshRate = 120
+)
+
+// recordValidator returns the field errors of a record about to be saved.
+// The returned error is reserved for failures unrelated to the record data.
+type recordValidator func(app core.App, record *core.Record) (validation.Errors, error)
+
+// recordValidators lists the validators of every validated collection.
+var recordValidators = map[string][]recordValidator{
+	advertisementConfigsCollectionName: {
+		validateAdConfigEnums,
+		validateAdConfigFields,
+		validateAdConfigAdUnits,
+		validateAdConfigTargeting,
+		validateAdConfigSchedule,
+	},
+	advertisementsPlacementsCollectionName: {
+		validatePlacementEnums,
+		validatePlacementFields,
+		validatePlacementAdUnit,
+		validatePlacementCatalogEntry,
+	},
+	placementsCollectionName: {
+		validatePlacementCatalog,
+	},
+	gamesCollectionName: {
+		validateGameBundleIDs,
+	},
+}
+
+// validateRecord runs every validator of the record collection and reports
+// all the field errors at once. Only the first error of every field is kept.
+//
+// Updates that don't change the record data (e.g. status changes) are not
+// validated again.
+func validateRecord(e *core.RecordRequestEvent) error {
+	if !e.Record.IsNew() && len(changedRecordFields(e.Record.Original(), e.Record)) == 0 {
+		return e.Next()
+	}
+
+	errs, err := recordValidationErrors(e.App, e.Record)
+	if err != nil {
+		return e.InternalServerError("failed to validate the record", err)
+	}
+
+	if len(errs) > 0 {
+		return e.BadRequestError("failed to validate the record", errs)
+	}
+
+	return e.Next()
+}
+
+// recordValidationErrors runs every validator of the record collection,
+// keeping only the first error of every field.
+func recordValidationErrors(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	for _, validator := range recordValidators[record.Collection().Name] {
+		fieldErrs, err := validator(app, record)
+		if err != nil {
+			return nil, err
+		}
+
+		for field, fieldErr := range fieldErrs {
+			if _, ok := errs[field]; !ok {
+				errs[field] = fieldErr
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+// validateAdConfigFields validates the advertisement config numeric settings.
+func validateAdConfigFields(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	refreshRate := record.GetFloat("banner_refresh_rate")
+	if refreshRate != 0 && (refreshRate < minBannerRefreshRate || refreshRate > maxBannerRefreshRate) {
+		errs["banner_refresh_rate"] = validation.NewError(
+			"validation_refresh_rate_out_of_range",
+			fmt.Sprintf("must be 0 (disabled) or between %d and %d seconds", minBannerRefreshRate, maxBannerRefreshRate),
+		)
+	}
+
+	if record.GetFloat("banner_memory_threshold") < 0 {
+		errs["banner_memory_threshold"] = validation.ErrMinGreaterEqualThanRequired.SetParams(map[string]any{"threshold": 0})
+	}
+
+	return errs, nil
+}
+
+// validateAdConfigAdUnits makes sure an update doesn't remove an ad unit
+// still used by the placements of the advertisement config.
+//
+// Only the removed ad units are checked so that the configs saved before
+// this validation existed can still be edited.
+func validateAdConfigAdUnits(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+	if record.IsNew() {
+		return errs, nil
+	}
+
+	original := record.Original()
+	removed := map[string]bool{}
+	for _, field := range adUnitFields {
+		if original.GetString(field) != "" && record.GetString(field) == "" {
+			removed[field] = true
+		}
+	}
+	if len(removed) == 0 {
+		return errs, nil
+	}
+
+	placements, err := app.FindAllRecords(
+		advertisementsPlacementsCollectionName,
+		dbx.HashExp{"advertisement_id": record.Id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, placement := range placements {
+		field := adUnitFields[adtypes.AdFormat(placement.GetInt("ad_format"))]
+		if removed[field] && placement.GetString("custom_ad_unit_id") == "" {
+			errs[field] = validation.NewError(
+				"validation_ad_unit_in_use",
+				fmt.Sprintf("used by the placement %s", placement.Id),
+			)
+		}
+	}
+
+	return errs, nil
+}
+
+// validatePlacementFields validates the advertisement placement numeric settings.
+func validatePlacementFields(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+
+	for _, field := range []string{"min_level", "time_between", "time_out", "retry", "delay_time"} {
+		if record.GetFloat(field) < 0 {
+			errs[field] = validation.ErrMinGreaterEqualThanRequired.SetParams(map[string]any{"threshold": 0})
+		}
+	}
+
+	return errs, nil
+}
+
+// adUnitFields maps the ad formats to the advertisement config field holding
+// their ad unit. The formats without such field require a custom ad unit.
+var adUnitFields = map[adtypes.AdFormat]string{
+	adtypes.AdFormatBanner:       "banner_ad_unit_id",
+	adtypes.AdFormatInterstitial: "interstitial_ad_unit_id",
+	adtypes.AdFormatRewarded:     "rewarded_ad_unit_id",
+}
+
+// validatePlacementAdUnit makes sure an ad unit is available for the
+// placement ad format, either from its advertisement config or its own
+// custom_ad_unit_id.
+func validatePlacementAdUnit(app core.App, record *core.Record) (validation.Errors, error) {
+	errs := validation.Errors{}
+	if record.GetString("custom_ad_unit_id") != "" {
+		return errs, nil
+	}
+
+	config, err := app.FindRecordById(advertisementConfigsCollectionName, record.GetString("advertisement_id"))
+	if err != nil {
+		// let the record validation report the invalid relation
+		return errs, nil
+	}
+
+	format := adtypes.AdFormat(record.GetInt("ad_format"))
+	field := adUnitFields[format]
+	if field == "" {
+		errs["custom_ad_unit_id"] = validation.NewError(
+			"validation_ad_unit_required",
+			fmt.Sprintf("required for the %s ad format", format),
+		)
+	} else if config.GetString(field) == "" {
+		errs["custom_ad_unit_id"] = validation.NewError(
+			"validation_ad_unit_required",
+			fmt.Sprintf("required since the advertisement config has no %s", field),
+		)
+	}
+
+	return errs, nil
+}
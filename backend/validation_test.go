@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordValidation(t *testing.T) {
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedClientConfig(t, app)
+		authenticateAsAdmin(t, app, e)
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "all advertisement config field errors at once",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			Body:   strings.NewReader(`{"banner_refresh_rate":10,"banner_memory_threshold":-1,"banner_position":9}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"banner_refresh_rate":{"code":"validation_refresh_rate_out_of_range","message":"Must be 0 (disabled) or between 30 and 120 seconds."}`,
+				`"banner_memory_threshold":{"code":"validation_min_greater_equal_than_required"`,
+				`"banner_position":{"code":"validation_unknown_enum_value"`,
+			},
+			BeforeTestFunc: seed,
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "disabled banner refresh",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			Body:   strings.NewReader(`{"banner_refresh_rate":0}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"banner_refresh_rate":0`},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:   "removing an ad unit used by a placement",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			Body:   strings.NewReader(`{"interstitial_ad_unit_id":""}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"interstitial_ad_unit_id":{"code":"validation_ad_unit_in_use","message":"Used by the placement ` + testPlacementRecordID + `."}`,
+			},
+			BeforeTestFunc: seed,
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "all placement field errors at once",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			Body:   strings.NewReader(`{"time_out":-1,"retry":-2,"delay_time":-0.5,"min_level":-3,"time_between":-4}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"time_out":{"code":"validation_min_greater_equal_than_required"`,
+				`"retry":{"code":"validation_min_greater_equal_than_required"`,
+				`"delay_time":{"code":"validation_min_greater_equal_than_required"`,
+				`"min_level":{"code":"validation_min_greater_equal_than_required"`,
+				`"time_between":{"code":"validation_min_greater_equal_than_required"`,
+			},
+			BeforeTestFunc: seed,
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "placement format without config ad unit",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			Body:   strings.NewReader(`{"ad_format":0}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"custom_ad_unit_id":{"code":"validation_ad_unit_required","message":"Required since the advertisement config has no banner_ad_unit_id."}`,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				config := seedClientConfig(t, app)
+				config.Set("banner_ad_unit_id", "")
+				require.NoError(t, app.Save(config))
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "placement with a custom ad unit",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			Body:   strings.NewReader(`{"ad_format":0,"custom_ad_unit_id":"custom-banner"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"custom_ad_unit_id":"custom-banner"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				config := seedClientConfig(t, app)
+				config.Set("banner_ad_unit_id", "")
+				require.NoError(t, app.Save(config))
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,547 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// versionFields lists the server maintained version bookkeeping fields.
+var versionFields = []string{"is_latest", "version", "lineage_id"}
+
+// systemFields lists the fields that are never copied between records.
+var systemFields = []string{"id", "created", "updated"}
+
+// configurationLineageFields lists the fields keying the configurations
+// lineages, which can't be changed by an update.
+var configurationLineageFields = []string{"game_id", "template_id"}
+
+// versionLineage returns the expression matching every version of the
+// record lineage.
+//
+// Configurations are versioned per (game, template) pair while
+// advertisement configs keep the id of their first version in lineage_id.
+func versionLineage(record *core.Record) dbx.HashExp {
+	if record.Collection().Name == configurationsCollectionName {
+		lineage := dbx.HashExp{}
+		for _, field := range configurationLineageFields {
+			lineage[field] = record.GetString(field)
+		}
+		return lineage
+	}
+
+	return dbx.HashExp{"lineage_id": record.GetString("lineage_id")}
+}
+
+// createFirstVersion marks a newly created record as the latest version of
+// its lineage.
+func createFirstVersion(e *core.RecordRequestEvent) error {
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		e.App = txApp
+
+		if e.Record.Collection().Name == advertisementConfigsCollectionName {
+			if e.Record.Id == "" {
+				e.Record.Id = core.GenerateDefaultRandomId()
+			}
+			e.Record.Set("lineage_id", e.Record.Id)
+		}
+
+		if err := prepareNextVersion(txApp, e.Record); err != nil {
+			return err
+		}
+
+		return e.Next()
+	})
+}
+
+// createVersionOnUpdate saves the updated record as a new latest version
+// instead of modifying the existing one, so that every version stays intact.
+func createVersionOnUpdate(e *core.RecordRequestEvent) error {
+	original := e.Record.Original()
+
+	// ignore client changes of the server maintained fields
+	for _, field := range versionFields {
+		if e.Record.Collection().Fields.GetByName(field) != nil {
+			e.Record.Set(field, original.Get(field))
+		}
+	}
+
+	// workflow only changes (e.g. archiving a published version) are
+	// applied in place (see enforceAdConfigWorkflow)
+	if len(changedRecordFields(original, e.Record)) == 0 {
+		return e.Next()
+	}
+
+	if !original.GetBool("is_latest") {
+		return e.BadRequestError("only the latest version can be changed", nil)
+	}
+
+	// a new version has to stay in the lineage of the updated record
+	if e.Record.Collection().Name == configurationsCollectionName {
+		errs := validation.Errors{}
+		for _, field := range configurationLineageFields {
+			if e.Record.GetString(field) != original.GetString(field) {
+				errs[field] = validation.NewError("validation_lineage_key", "can't be changed, create a new configuration instead")
+			}
+		}
+		if len(errs) > 0 {
+			return e.BadRequestError("failed to validate the record", errs)
+		}
+	}
+
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		e.App = txApp
+
+		next := copyRecord(e.Record)
+		if next.Collection().Name == advertisementConfigsCollectionName {
+			next.Set("lineage_id", original.GetString("lineage_id"))
+			startDraft(next, e.Auth)
+		}
+
+		if err := prepareNextVersion(txApp, next); err != nil {
+			return err
+		}
+
+		// the default handler persists (and responds with) the new version
+		e.Record = next
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		if next.Collection().Name == advertisementConfigsCollectionName {
+			_, err := copyAdvertisementPlacements(txApp, original, next)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// deleteVersionLineage deletes every version of the record when its latest
+// version is deleted. Previous versions can't be deleted individually.
+func deleteVersionLineage(e *core.RecordRequestEvent) error {
+	if !e.Record.GetBool("is_latest") {
+		return e.BadRequestError("only the latest version can be deleted", nil)
+	}
+
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		e.App = txApp
+
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		versions, err := txApp.FindAllRecords(e.Record.Collection(), versionLineage(e.Record))
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			if err := txApp.Delete(version); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// handleConfigRollback creates a new latest version with the content of
+// a previous version of a configuration or advertisement config, including
+// a copy of the previous version placements.
+func handleConfigRollback(e *core.RequestEvent) error {
+	var body struct {
+		Version int `json:"version"`
+	}
+	if err := e.BindBody(&body); err != nil || body.Version <= 0 {
+		return e.BadRequestError("a positive target version is required", err)
+	}
+
+	record, err := findVersionedRecord(e.App, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("config not found", nil)
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+	canUpdate, err := e.App.CanAccessRecord(record, requestInfo, record.Collection().UpdateRule)
+	if !canUpdate {
+		return e.ForbiddenError("you are not allowed to change this config", err)
+	}
+
+	if record.Collection().Name == advertisementConfigsCollectionName {
+		if err := requireGameRole(e, rolePublisher, configGameIDs(record)...); err != nil {
+			return err
+		}
+	}
+
+	target, err := findLineageVersion(e.App, record, body.Version)
+	if err != nil {
+		return e.NotFoundError(fmt.Sprintf("version %d not found", body.Version), nil)
+	}
+
+	if target.GetBool("is_latest") {
+		return e.BadRequestError(fmt.Sprintf("version %d is already the latest one", body.Version), nil)
+	}
+
+	var next *core.Record
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		latest, err := txApp.FindAllRecords(record.Collection(), versionLineage(record), dbx.HashExp{"is_latest": true})
+		if err != nil {
+			return err
+		}
+
+		next, err = rollbackToVersion(txApp, e, target)
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(auditedCollections, next.Collection().Name) || len(latest) == 0 {
+			return nil
+		}
+
+		gameIDs := configGameIDs(next)
+		for _, id := range configGameIDs(latest[0]) {
+			if !slices.Contains(gameIDs, id) {
+				gameIDs = append(gameIDs, id)
+			}
+		}
+
+		return writeAuditEntry(txApp, e, auditActionUpdate, latest[0], next, gameIDs)
+	})
+	if err != nil {
+		return e.BadRequestError("failed to roll back", err)
+	}
+
+	if err := apis.EnrichRecord(e, next); err != nil {
+		return e.InternalServerError("failed to enrich record", err)
+	}
+
+	return e.JSON(http.StatusOK, next)
+}
+
+// rollbackToVersion saves a copy of the target version as the new latest
+// version of its lineage.
+//
+// Rolling an advertisement config back to a previously published version
+// publishes the copy right away, since its content was already approved.
+// Otherwise the copy starts as a draft authored by the request auth record.
+//
+// It must be called inside a transaction.
+func rollbackToVersion(txApp core.App, e *core.RequestEvent, target *core.Record) (*core.Record, error) {
+	next := copyRecord(target)
+	republish := false
+	if next.Collection().Name == advertisementConfigsCollectionName {
+		next.Set("lineage_id", target.GetString("lineage_id"))
+		startDraft(next, e.Auth)
+
+		status := target.GetString("status")
+		if status == statusPublished || status == statusArchived {
+			republish = true
+			next.Set("status", statusPublished)
+			next.Set("approved_by", target.GetString("approved_by"))
+		}
+	}
+
+	if err := prepareNextVersion(txApp, next); err != nil {
+		return nil, err
+	}
+
+	if err := txApp.Save(next); err != nil {
+		return nil, err
+	}
+
+	if next.Collection().Name == advertisementConfigsCollectionName {
+		if _, err := copyAdvertisementPlacements(txApp, target, next); err != nil {
+			return nil, err
+		}
+	}
+
+	if republish {
+		if err := archivePublishedVersions(txApp, e, next); err != nil {
+			return nil, err
+		}
+	}
+
+	return next, nil
+}
+
+// findVersionedRecord looks up the record with the given id in the
+// versioned collections.
+func findVersionedRecord(app core.App, id string) (*core.Record, error) {
+	record, err := app.FindRecordById(advertisementConfigsCollectionName, id)
+	if err == nil {
+		return record, nil
+	}
+
+	return app.FindRecordById(configurationsCollectionName, id)
+}
+
+// findLineageVersion returns the version with the given number from the
+// lineage of the record.
+func findLineageVersion(app core.App, record *core.Record, version int) (*core.Record, error) {
+	versions, err := app.FindAllRecords(
+		record.Collection(),
+		versionLineage(record),
+		dbx.HashExp{"version": version},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("version %d not found", version)
+	}
+
+	return versions[0], nil
+}
+
+// prepareNextVersion assigns the next version number of the lineage to the
+// not yet saved record and unmarks the current latest version.
+//
+// It must be called inside a transaction.
+func prepareNextVersion(txApp core.App, record *core.Record) error {
+	collection := record.Collection()
+	lineage := versionLineage(record)
+
+	var lastVersion float64
+	err := txApp.DB().
+		Select("COALESCE(MAX([[version]]), 0)").
+		From(collection.Name).
+		Where(lineage).
+		Row(&lastVersion)
+	if err != nil {
+		return err
+	}
+
+	// the previous versions are updated directly so that their updated
+	// date and content remain untouched
+	latest := dbx.HashExp{"is_latest": true}
+	_, err = txApp.DB().
+		Update(collection.Name, dbx.Params{"is_latest": false}, dbx.And(lineage, latest)).
+		Execute()
+	if err != nil {
+		return err
+	}
+
+	record.Set("version", int(lastVersion)+1)
+	record.Set("is_latest", true)
+
+	return nil
+}
+
+// forkAdvertisementConfig creates a new latest version of the advertisement
+// config with a copy of all of its placements.
+//
+// The new version starts as a draft authored by the given user. It returns
+// the new version and its placements indexed by the id of the placement
+// they were copied from.
+func forkAdvertisementConfig(txApp core.App, config *core.Record, author *core.Record) (*core.Record, map[string]*core.Record, error) {
+	next := copyRecord(config)
+	next.Set("lineage_id", config.GetString("lineage_id"))
+	startDraft(next, author)
+
+	if err := prepareNextVersion(txApp, next); err != nil {
+		return nil, nil, err
+	}
+
+	if err := txApp.Save(next); err != nil {
+		return nil, nil, err
+	}
+
+	placements, err := copyAdvertisementPlacements(txApp, config, next)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return next, placements, nil
+}
+
+// copyAdvertisementPlacements copies the placements of one advertisement
+// config version to another, returning the copies indexed by source id.
+func copyAdvertisementPlacements(txApp core.App, from *core.Record, to *core.Record) (map[string]*core.Record, error) {
+	placements, err := txApp.FindAllRecords(
+		advertisementsPlacementsCollectionName,
+		dbx.HashExp{"advertisement_id": from.Id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	copies := make(map[string]*core.Record, len(placements))
+	for _, placement := range placements {
+		placementCopy := copyRecord(placement)
+		placementCopy.Set("advertisement_id", to.Id)
+
+		if err := txApp.Save(placementCopy); err != nil {
+			return nil, err
+		}
+
+		copies[placement.Id] = placementCopy
+	}
+
+	return copies, nil
+}
+
+// versionPlacementCreate adds the new placement to a new version of its
+// advertisement config.
+func versionPlacementCreate(e *core.RecordRequestEvent) error {
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		e.App = txApp
+
+		next, _, err := forkPlacementParent(e, e.Record.GetString("advertisement_id"))
+		if err != nil {
+			return err
+		}
+
+		e.Record.Set("advertisement_id", next.Id)
+
+		return e.Next()
+	})
+}
+
+// versionPlacementUpdate applies the placement changes to its copy in a new
+// version of the advertisement config.
+func versionPlacementUpdate(e *core.RecordRequestEvent) error {
+	original := e.Record.Original()
+
+	if e.Record.GetString("advertisement_id") != original.GetString("advertisement_id") {
+		return e.BadRequestError("placements can't be moved to another advertisement config", nil)
+	}
+
+	changed := changedRecordFields(original, e.Record)
+	if len(changed) == 0 {
+		return e.Next()
+	}
+
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		e.App = txApp
+
+		_, copies, err := forkPlacementParent(e, original.GetString("advertisement_id"))
+		if err != nil {
+			return err
+		}
+
+		placement := copies[e.Record.Id]
+		for _, field := range changed {
+			placement.Set(field, e.Record.Get(field))
+		}
+
+		e.Record = placement
+
+		return e.Next()
+	})
+}
+
+// versionPlacementDelete removes the placement from a new version of its
+// advertisement config.
+func versionPlacementDelete(e *core.RecordRequestEvent) error {
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		e.App = txApp
+
+		_, copies, err := forkPlacementParent(e, e.Record.GetString("advertisement_id"))
+		if err != nil {
+			return err
+		}
+
+		e.Record = copies[e.Record.Id]
+
+		return e.Next()
+	})
+}
+
+func findLatestPlacementParent(e *core.RecordRequestEvent, configID string) (*core.Record, error) {
+	config, err := e.App.FindRecordById(advertisementConfigsCollectionName, configID)
+	if err != nil {
+		return nil, e.BadRequestError("advertisement config not found", nil)
+	}
+
+	if !config.GetBool("is_latest") {
+		return nil, e.BadRequestError("only the placements of the latest advertisement config version can be changed", nil)
+	}
+
+	return config, nil
+}
+
+// forkPlacementParent creates the new version of the latest advertisement
+// config whose placement is changed by the request and records it in the
+// audit log. It returns the new version and its placements indexed by the
+// id of the placement they were copied from.
+//
+// It must be called inside a transaction.
+func forkPlacementParent(e *core.RecordRequestEvent, configID string) (*core.Record, map[string]*core.Record, error) {
+	config, err := findLatestPlacementParent(e, configID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next, copies, err := forkAdvertisementConfig(e.App, config, e.Auth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := writeAuditEntry(e.App, e.RequestEvent, auditActionUpdate, config, next, configGameIDs(next)); err != nil {
+		return nil, nil, err
+	}
+
+	return next, copies, nil
+}
+
+// copyRecord returns a new unsaved record with the content of the source
+// record, excluding its system, version and workflow fields.
+func copyRecord(source *core.Record) *core.Record {
+	record := core.NewRecord(source.Collection())
+
+	for _, field := range source.Collection().Fields {
+		name := field.GetName()
+		if slices.Contains(systemFields, name) || slices.Contains(versionFields, name) ||
+			slices.Contains(workflowFields, name) {
+			continue
+		}
+		record.Set(name, source.Get(name))
+	}
+
+	return record
+}
+
+// changedRecordFields returns the names of the content fields whose value
+// differs between the two records, ignoring the system, version and
+// workflow fields.
+func changedRecordFields(original *core.Record, updated *core.Record) []string {
+	var changed []string
+
+	for _, field := range updated.Collection().Fields {
+		name := field.GetName()
+		if slices.Contains(systemFields, name) || slices.Contains(versionFields, name) ||
+			slices.Contains(workflowFields, name) {
+			continue
+		}
+		if !recordValuesEqual(original.Get(name), updated.Get(name)) {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed
+}
+
+func recordValuesEqual(a any, b any) bool {
+	rawA, isRawA := a.(types.JSONRaw)
+	rawB, isRawB := b.(types.JSONRaw)
+	if isRawA || isRawB {
+		var valueA, valueB any
+		_ = json.Unmarshal(rawA, &valueA)
+		_ = json.Unmarshal(rawB, &valueB)
+		return reflect.DeepEqual(valueA, valueB)
+	}
+
+	return reflect.DeepEqual(a, b)
+}
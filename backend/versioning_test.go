@@ -0,0 +1,265 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findLatestTestConfig returns the latest version of the seeded advertisement config.
+func findLatestTestConfig(t testing.TB, app core.App) *core.Record {
+	latest, err := app.FindFirstRecordByFilter(
+		advertisementConfigsCollectionName,
+		"lineage_id = {:lineage} && is_latest = true",
+		dbx.Params{"lineage": testConfigRecordID},
+	)
+	require.NoError(t, err, "Failed to find the latest advertisement config version")
+
+	return latest
+}
+
+const testSecondConfigRecordID = "testconfig00002"
+
+// seedSecondConfigVersion seeds the client config with a second, latest, advertisement config version.
+func seedSecondConfigVersion(t testing.TB, app core.App) {
+	first := seedClientConfig(t, app)
+	first.Set("is_latest", false)
+	first.Set("status", statusArchived)
+	require.NoError(t, app.Save(first))
+
+	createTestRecord(t, app, advertisementConfigsCollectionName, map[string]any{
+		"id":            testSecondConfigRecordID,
+		"lineage_id":    testConfigRecordID,
+		"version":       2,
+		"is_latest":     true,
+		"status":        statusPublished,
+		"name":          "default",
+		"experiment_id": "control",
+		"game_id":       []string{testGameRecordID},
+	})
+}
+
+func TestConfigVersioning(t *testing.T) {
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedClientConfig(t, app)
+		authenticateAsAdmin(t, app, e)
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "updating an advertisement config creates a new version",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			Body:   strings.NewReader(`{"banner_refresh_rate":90,"version":10,"is_latest":false}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"banner_refresh_rate":90`,
+				`"version":2`,
+				`"is_latest":true`,
+				`"lineage_id":"` + testConfigRecordID + `"`,
+				`"status":"draft"`,
+			},
+			NotExpectedContent: []string{`"id":"` + testConfigRecordID + `"`},
+			BeforeTestFunc:     seed,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				previous, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+				require.NoError(t, err)
+				assert.False(t, previous.GetBool("is_latest"), "Previous version should no longer be latest")
+				assert.Equal(t, 60, previous.GetInt("banner_refresh_rate"), "Previous version should stay unchanged")
+
+				latest := findLatestTestConfig(t, app)
+				placements, err := app.FindAllRecords(
+					advertisementsPlacementsCollectionName,
+					dbx.HashExp{"advertisement_id": latest.Id},
+				)
+				require.NoError(t, err)
+				assert.Len(t, placements, 2, "Placements should be copied to the new version")
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "previous versions can't be changed",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			Body:   strings.NewReader(`{"banner_refresh_rate":90}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"Only the latest version can be changed."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				previous, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+				require.NoError(t, err)
+				_, _, err = forkAdvertisementConfig(app, previous, nil)
+				require.NoError(t, err)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "updating a placement creates a new advertisement config version",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			Body:   strings.NewReader(`{"min_level":10}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:     200,
+			ExpectedContent:    []string{`"min_level":10`, `"ad_format":1`},
+			NotExpectedContent: []string{`"advertisement_id":"` + testConfigRecordID + `"`},
+			BeforeTestFunc:     seed,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				previous, err := app.FindRecordById(advertisementsPlacementsCollectionName, testPlacementRecordID)
+				require.NoError(t, err)
+				assert.Equal(t, 3, previous.GetInt("min_level"), "Previous placement should stay unchanged")
+
+				latest := findLatestTestConfig(t, app)
+				assert.Equal(t, 2, latest.GetInt("version"))
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "deleting a placement keeps it in the previous version",
+			Method:         http.MethodDelete,
+			URL:            "/api/collections/advertisements_placements/records/" + testPlacementRecordID,
+			ExpectedStatus: 204,
+			BeforeTestFunc: seed,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				_, err := app.FindRecordById(advertisementsPlacementsCollectionName, testPlacementRecordID)
+				assert.NoError(t, err, "Previous placement should still exist")
+
+				latest := findLatestTestConfig(t, app)
+				placements, err := app.FindAllRecords(
+					advertisementsPlacementsCollectionName,
+					dbx.HashExp{"advertisement_id": latest.Id},
+				)
+				require.NoError(t, err)
+				assert.Len(t, placements, 1)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "previous versions can't be deleted",
+			Method:          http.MethodDelete,
+			URL:             "/api/collections/advertisement_configs/records/" + testConfigRecordID,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"Only the latest version can be deleted."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedSecondConfigVersion(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:           "deleting the latest version deletes the whole lineage",
+			Method:         http.MethodDelete,
+			URL:            "/api/collections/advertisement_configs/records/" + testSecondConfigRecordID,
+			ExpectedStatus: 204,
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedSecondConfigVersion(t, app)
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				total, err := app.CountRecords(advertisementConfigsCollectionName)
+				require.NoError(t, err)
+				assert.Zero(t, total)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestConfigRollback(t *testing.T) {
+	seed := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		seedSecondConfigVersion(t, app)
+		authenticateAsAdmin(t, app, e)
+	}
+
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:            "unauthenticated",
+			Method:          http.MethodPost,
+			URL:             "/api/configs/" + testSecondConfigRecordID + "/rollback",
+			Body:            strings.NewReader(`{"version":1}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedSecondConfigVersion(t, app)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "missing version",
+			Method:          http.MethodPost,
+			URL:             "/api/configs/" + testSecondConfigRecordID + "/rollback",
+			Body:            strings.NewReader(`{"version":7}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{"Version 7 not found."},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:            "already latest version",
+			Method:          http.MethodPost,
+			URL:             "/api/configs/" + testConfigRecordID + "/rollback",
+			Body:            strings.NewReader(`{"version":2}`),
+			Headers:         map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"Version 2 is already the latest one."},
+			BeforeTestFunc:  seed,
+			TestAppFactory:  setupTestApp,
+		},
+		{
+			Name:           "rollback to a previous version",
+			Method:         http.MethodPost,
+			URL:            "/api/configs/" + testSecondConfigRecordID + "/rollback",
+			Body:           strings.NewReader(`{"version":1}`),
+			Headers:        map[string]string{"Content-Type": "application/json"},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"version":3`,
+				`"is_latest":true`,
+				`"banner_ad_unit_id":"banner-unit"`,
+				`"banner_refresh_rate":60`,
+				`"status":"published"`,
+			},
+			BeforeTestFunc: seed,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				latest := findLatestTestConfig(t, app)
+				assert.Equal(t, 3, latest.GetInt("version"))
+
+				previous, err := app.FindRecordById(advertisementConfigsCollectionName, testSecondConfigRecordID)
+				require.NoError(t, err)
+				assert.Equal(t, statusArchived, previous.GetString("status"), "Replaced version should be archived")
+
+				placements, err := app.FindAllRecords(
+					advertisementsPlacementsCollectionName,
+					dbx.HashExp{"advertisement_id": latest.Id},
+				)
+				require.NoError(t, err)
+				assert.Len(t, placements, 2, "Placements of the target version should be re-created")
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Advertisement config statuses.
+const (
+	statusDraft     = "draft"
+	statusInReview  = "in_review"
+	statusApproved  = "approved"
+	statusPublished = "published"
+	statusArchived  = "archived"
+)
+
+// workflowFields lists the advertisement config fields maintained by the
+// publishing workflow instead of being part of the config content.
+var workflowFields = []string{"status", "author", "approved_by"}
+
+// statusTransitions lists the allowed status changes of an advertisement config.
+var statusTransitions = map[string][]string{
+	statusDraft:     {statusInReview},
+	statusInReview:  {statusApproved, statusDraft},
+	statusApproved:  {statusPublished, statusDraft},
+	statusPublished: {statusArchived},
+	statusArchived:  {},
+}
+
+// startAdConfigDraft makes every newly created advertisement config a draft
+// authored by the current user.
+func startAdConfigDraft(e *core.RecordRequestEvent) error {
+	startDraft(e.Record, e.Auth)
+
+	return e.Next()
+}
+
+// enforceAdConfigWorkflow validates the advertisement config status changes.
+//
+// Content changes always produce a new draft version (see createVersionOnUpdate)
+// so they can't be combined with a status change.
+func enforceAdConfigWorkflow(e *core.RecordRequestEvent) error {
+	original := e.Record.Original()
+
+	// the author and approver are maintained by the server
+	e.Record.Set("author", original.GetString("author"))
+	e.Record.Set("approved_by", original.GetString("approved_by"))
+
+	from := original.GetString("status")
+	to := e.Record.GetString("status")
+	if from == to {
+		return e.Next()
+	}
+
+	if len(changedRecordFields(original, e.Record)) > 0 {
+		return e.BadRequestError("the status and the content of an advertisement config can't be changed at once", nil)
+	}
+
+	if !slices.Contains(statusTransitions[from], to) {
+		return e.BadRequestError(fmt.Sprintf("can't change the status from %q to %q", from, to), nil)
+	}
+
+	switch to {
+	case statusApproved:
+		if e.Auth == nil || e.Auth.Id == original.GetString("author") {
+			return e.ForbiddenError("the approval must be performed by a different user than the author", nil)
+		}
+		e.Record.Set("approved_by", e.Auth.Id)
+	case statusDraft:
+		e.Record.Set("approved_by", "")
+	case statusPublished:
+		return e.App.RunInTransaction(func(txApp core.App) error {
+			e.App = txApp
+
+			if err := archivePublishedVersions(txApp, e.RequestEvent, e.Record); err != nil {
+				return err
+			}
+
+			return e.Next()
+		})
+	}
+
+	return e.Next()
+}
+
+// startDraft resets the workflow fields of a new advertisement config version.
+func startDraft(record *core.Record, author *core.Record) {
+	record.Set("status", statusDraft)
+	record.Set("approved_by", "")
+	if author != nil {
+		record.Set("author", author.Id)
+	} else {
+		record.Set("author", "")
+	}
+}
+
+// archivePublishedVersions archives the published versions of the record
+// lineage (except the record itself) so that there is only a single
+// published version served to the game clients. The archived versions are
+// recorded in the audit log as changes of the request auth record.
+func archivePublishedVersions(txApp core.App, e *core.RequestEvent, record *core.Record) error {
+	published, err := txApp.FindAllRecords(
+		advertisementConfigsCollectionName,
+		versionLineage(record),
+		dbx.HashExp{"status": statusPublished},
+		dbx.Not(dbx.HashExp{"id": record.Id}),
+	)
+	if err != nil || len(published) == 0 {
+		return err
+	}
+
+	// the versions are updated directly so that their updated date remains
+	// the one of their content
+	_, err = txApp.DB().
+		Update(
+			advertisementConfigsCollectionName,
+			dbx.Params{"status": statusArchived},
+			dbx.And(
+				versionLineage(record),
+				dbx.HashExp{"status": statusPublished},
+				dbx.Not(dbx.HashExp{"id": record.Id}),
+			),
+		).
+		Execute()
+	if err != nil {
+		return err
+	}
+
+	for _, version := range published {
+		archived := version.Fresh()
+		archived.Set("status", statusArchived)
+		if err := writeAuditEntry(txApp, e, auditActionUpdate, version, archived, configGameIDs(version)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userEmail = "moderator@sun.studio"
+
+// authenticateAsUser makes every scenario request authenticated as the seeded regular user.
+func authenticateAsUser(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+	user, err := app.FindAuthRecordByEmail("users", userEmail)
+	require.NoError(t, err, "Failed to find regular user")
+
+	authenticateAs(t, e, user)
+}
+
+// seedConfigWithStatus seeds the client config and moves its second,
+// latest, version (authored by the admin) to the given status.
+func seedConfigWithStatus(t testing.TB, app core.App, status string) {
+	seedSecondConfigVersion(t, app)
+
+	admin, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, adminEmail)
+	require.NoError(t, err)
+
+	config, err := app.FindRecordById(advertisementConfigsCollectionName, testSecondConfigRecordID)
+	require.NoError(t, err)
+	config.Set("status", status)
+	config.Set("author", admin.Id)
+	require.NoError(t, app.Save(config))
+}
+
+func TestAdConfigWorkflow(t *testing.T) {
+	scenarios := []*tests.ApiScenario{
+		{
+			Name:   "new advertisement configs start as drafts",
+			Method: http.MethodPost,
+			URL:    "/api/collections/advertisement_configs/records",
+			Body:   strings.NewReader(`{"name":"new","experiment_id":"control","game_id":["` + testGameRecordID + `"],"status":"published"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"status":"draft"`, `"approved_by":""`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedClientConfig(t, app)
+				seedGameMember(t, app, roleEditor)
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				user, err := app.FindAuthRecordByEmail("users", userEmail)
+				require.NoError(t, err)
+
+				config, err := app.FindFirstRecordByData(advertisementConfigsCollectionName, "name", "new")
+				require.NoError(t, err)
+				assert.Equal(t, user.Id, config.GetString("author"))
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "drafts can't skip the review",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testSecondConfigRecordID,
+			Body:   strings.NewReader(`{"status":"published"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`Can't change the status from \"draft\" to \"published\".`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigWithStatus(t, app, statusDraft)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "status and content can't be changed at once",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testSecondConfigRecordID,
+			Body:   strings.NewReader(`{"status":"in_review","banner_refresh_rate":90}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{"The status and the content of an advertisement config can't be changed at once."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigWithStatus(t, app, statusDraft)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "submitting a draft for review",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testSecondConfigRecordID,
+			Body:   strings.NewReader(`{"status":"in_review"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"id":"` + testSecondConfigRecordID + `"`, `"status":"in_review"`, `"version":2`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigWithStatus(t, app, statusDraft)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "the author can't approve their own config",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testSecondConfigRecordID,
+			Body:   strings.NewReader(`{"status":"approved"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{"The approval must be performed by a different user than the author."},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigWithStatus(t, app, statusInReview)
+				authenticateAsAdmin(t, app, e)
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "another user approves the config",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testSecondConfigRecordID,
+			Body:   strings.NewReader(`{"status":"approved","approved_by":"someone"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"status":"approved"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigWithStatus(t, app, statusInReview)
+				seedGameMember(t, app, rolePublisher)
+				authenticateAsUser(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				user, err := app.FindAuthRecordByEmail("users", userEmail)
+				require.NoError(t, err)
+
+				config, err := app.FindRecordById(advertisementConfigsCollectionName, testSecondConfigRecordID)
+				require.NoError(t, err)
+				assert.Equal(t, user.Id, config.GetString("approved_by"))
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:   "publishing archives the previously published version",
+			Method: http.MethodPatch,
+			URL:    "/api/collections/advertisement_configs/records/" + testSecondConfigRecordID,
+			Body:   strings.NewReader(`{"status":"published"}`),
+			Headers: map[string]string{
+				"Content-Type": "application/json",
+			},
+			ExpectedStatus:  200,
+			ExpectedContent: []string{`"status":"published"`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigWithStatus(t, app, statusApproved)
+
+				first, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+				require.NoError(t, err)
+				first.Set("status", statusPublished)
+				require.NoError(t, app.Save(first))
+
+				authenticateAsAdmin(t, app, e)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				first, err := app.FindRecordById(advertisementConfigsCollectionName, testConfigRecordID)
+				require.NoError(t, err)
+				assert.Equal(t, statusArchived, first.GetString("status"))
+
+				game, err := app.FindRecordById(gamesCollectionName, testGameRecordID)
+				require.NoError(t, err)
+				config, err := resolveClientConfig(app, game, clientContext{})
+				require.NoError(t, err)
+				assert.Equal(t, testSecondConfigRecordID, config.ConfigID, "Clients should get the newly published version")
+			},
+			TestAppFactory: setupTestApp,
+		},
+		{
+			Name:            "unpublished configs are not served to the clients",
+			Method:          http.MethodGet,
+			URL:             "/api/client-config/" + testGameID,
+			Headers:         map[string]string{clientKeyHeader: testClientKey},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				seedConfigWithStatus(t, app, statusApproved)
+			},
+			TestAppFactory: setupTestApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -14,6 +14,7 @@ import {
   DashboardOutlined,
   AppstoreOutlined,
   ClusterOutlined,
+  HistoryOutlined,
 } from '@ant-design/icons';
 import { authProvider } from './authProvider';
 import { dataProvider } from './dataProvider';
@@ -32,6 +33,7 @@ import {
   AdvertisementPlacementCreate,
   AdvertisementPlacementEdit,
 } from './pages/advertisement-placements';
+import { AuditLogList } from './pages/audit-log';
 import { AuthPage } from './pages/auth';
 import { useTranslation } from 'react-i18next';
 import { Header, Title } from './components';
@@ -102,6 +104,14 @@ const AppContent: React.FC = () => {
         parent: 'advertisements',
       },
     },
+    {
+      name: 'audit_log',
+      list: '/audit-log',
+      meta: {
+        label: 'Audit Log',
+        icon: <HistoryOutlined />,
+      },
+    },
   ];
 
   const i18nProvider = {
@@ -153,6 +163,8 @@ const AppContent: React.FC = () => {
             <Route path="edit/:id" element={<AdvertisementPlacementEdit />} />
             <Route path=":id" element={<AdvertisementPlacementShow />} />
           </Route>
+
+          <Route path="/audit-log" element={<AuditLogList />} />
         </Route>
 
         <Route
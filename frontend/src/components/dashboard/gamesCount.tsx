@@ -1,19 +1,21 @@
-import { useList } from '@refinedev/core';
-import { Typography, Spin } from 'antd';
+import { useCustom, useTranslate } from '@refinedev/core';
+import { Typography, Spin, Row, Col, Statistic } from 'antd';
 import React from 'react';
-import type { IGame } from '../../interfaces';
+import type { IGamesOverview } from '../../interfaces';
 
 export const GamesCount: React.FC = () => {
-  const { query } = useList<IGame>({
-    resource: 'games',
-    pagination: {
-      pageSize: 1, // We only need the total count, not the actual data
-    },
+  const t = useTranslate();
+
+  // Per-game counts aggregated by the server
+  const { query } = useCustom<IGamesOverview>({
+    url: '/api/games/overview',
+    method: 'get',
   });
 
   const { data, isLoading } = query;
 
-  const totalGames = data?.total || 0;
+  const totals = data?.data?.totals;
+  const totalGames = totals?.games || 0;
 
   return (
     <div
@@ -34,8 +36,25 @@ export const GamesCount: React.FC = () => {
             {totalGames}
           </Typography.Title>
           <Typography.Text type="secondary" style={{ fontSize: '16px' }}>
-            Total Games
+            {t('games.overview.totalGames')}
           </Typography.Text>
+          <Row gutter={[24, 16]} style={{ marginTop: '24px', width: '100%' }}>
+            <Col span={6}>
+              <Statistic title={t('games.overview.liveGames')} value={totals?.live_games || 0} />
+            </Col>
+            <Col span={6}>
+              <Statistic title={t('games.overview.adConfigs')} value={totals?.ad_configs || 0} />
+            </Col>
+            <Col span={6}>
+              <Statistic title={t('games.overview.placements')} value={totals?.placements || 0} />
+            </Col>
+            <Col span={6}>
+              <Statistic
+                title={t('games.overview.activeExperiments')}
+                value={totals?.active_experiments || 0}
+              />
+            </Col>
+          </Row>
         </>
       )}
     </div>
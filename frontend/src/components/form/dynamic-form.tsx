@@ -1,4 +1,5 @@
 import React from 'react';
+import { useSelect } from '@refinedev/antd';
 import { Form, Input, Switch, InputNumber, Select, DatePicker } from 'antd';
 import TextArea from 'antd/es/input/TextArea';
 
@@ -20,6 +21,9 @@ export interface DynamicField {
   // Select field properties
   values?: string[];
   maxSelect?: number;
+  // Relation field properties
+  collectionId?: string;
+  displayField?: string;
   // Additional properties that might be present in different field types
   [key: string]: unknown;
 }
@@ -30,6 +34,27 @@ interface DynamicFormProps {
   layout?: 'vertical' | 'horizontal';
 }
 
+// Select input listing the records of the related collection,
+// labelled with their displayField (the record id by default)
+const RelationSelect = ({ field, ...props }: { field: DynamicField; [key: string]: unknown }) => {
+  const { selectProps } = useSelect({
+    resource: field.collectionId || '',
+    optionLabel: field.displayField || 'id',
+    optionValue: 'id',
+    queryOptions: { enabled: !!field.collectionId },
+  });
+
+  return (
+    <Select
+      {...selectProps}
+      {...props}
+      mode={field.maxSelect && field.maxSelect > 1 ? 'multiple' : undefined}
+      placeholder={`Select ${field.name}`}
+      allowClear={!field.required}
+    />
+  );
+};
+
 // Map PocketBase field types to Ant Design components
 const renderField = (field: DynamicField) => {
   const commonProps = {
@@ -222,14 +247,10 @@ const renderField = (field: DynamicField) => {
       );
 
     case 'relation':
-      // For relation fields, we'll render a select input
-      // In a real implementation, you'd want to fetch related records
+      // For relation fields, we'll render a select input listing the related records
       return (
         <Form.Item key={field.id} {...commonProps}>
-          <Select
-            mode={field.maxSelect && field.maxSelect > 1 ? 'multiple' : undefined}
-            placeholder={`Select ${field.name}`}
-          />
+          <RelationSelect field={field} />
         </Form.Item>
       );
 
@@ -25,12 +25,15 @@ export const dataProvider: DataProvider = {
             return '';
           }
 
+          // Booleans are compared as is, a quoted "true" never matching a bool field
+          const operand = typeof value === 'boolean' ? `${value}` : `"${value}"`;
+
           // Handle different operators according to PocketBase filter syntax
           switch (operator) {
             case 'eq':
-              return `${field} = "${value}"`;
+              return `${field} = ${operand}`;
             case 'ne':
-              return `${field} != "${value}"`;
+              return `${field} != ${operand}`;
             case 'contains':
               return `${field} ~ "${value}"`;
             case 'ncontains':
@@ -194,10 +197,19 @@ export const dataProvider: DataProvider = {
 
   custom: async ({ url, method, filters, sorters, payload, query, headers }) => {
     // For custom requests, we'll use the fetch API directly
-    const response = await fetch(`${pb.baseUrl}${url}`, {
+    const params = new URLSearchParams();
+    Object.entries(query || {}).forEach(([key, value]) => {
+      if (value !== undefined && value !== null && value !== '') {
+        params.append(key, String(value));
+      }
+    });
+    const search = params.toString() ? `?${params}` : '';
+
+    const response = await fetch(`${pb.baseUrl}${url}${search}`, {
       method: method || 'GET',
       headers: {
         'Content-Type': 'application/json',
+        ...(pb.authStore.token ? { Authorization: pb.authStore.token } : {}),
         ...headers,
       },
       body: payload ? JSON.stringify(payload) : undefined,
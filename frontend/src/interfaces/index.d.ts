@@ -34,6 +34,7 @@ export interface IConfiguration {
   data: any;
   game_id: string;
   template_id: string;
+  version: number;
   is_latest: boolean;
   created: string;
   updated: string;
@@ -47,6 +48,7 @@ export interface IConfigurationTemplate {
   id: string;
   name: string;
   data: any;
+  schema?: any;
   game_id: string;
   created: string;
   updated: string;
@@ -56,10 +58,79 @@ export interface IConfigurationTemplateFilterVariables {
   game_id?: string;
 }
 
+export type GameStatus = 'development' | 'live' | 'sunset';
+
 export interface IGame {
   id: string;
   game_id: string;
+  ios_bundle_id?: string;
+  android_package_name?: string;
+  name?: string;
+  platforms?: ('ios' | 'android')[];
+  app_store_url?: string;
+  play_store_url?: string;
+  icon?: string;
+  team?: string;
+  status?: GameStatus;
   created: string;
+  updated: string;
+}
+
+export type GameRole = 'viewer' | 'editor' | 'publisher' | 'owner';
+
+export interface IGameMember {
+  id: string;
+  user: string;
+  game_id: string;
+  role: GameRole;
+  created: string;
+  updated: string;
+}
+
+export interface IGameOverview {
+  id: string;
+  game_id: string;
+  name: string;
+  status: GameStatus | '';
+  ad_configs: number;
+  placements: number;
+  active_experiments: number;
+  last_modified: string;
+}
+
+export interface IGamesOverview {
+  games: IGameOverview[];
+  totals: {
+    games: number;
+    live_games: number;
+    ad_configs: number;
+    placements: number;
+    active_experiments: number;
+  };
+}
+
+export type AuditAction = 'create' | 'update' | 'delete';
+
+export interface IAuditLogEntry {
+  id: string;
+  actor: string;
+  actor_email: string;
+  action: AuditAction;
+  collection: string;
+  record_id: string;
+  game_ids: string;
+  fields: string;
+  changes: Record<string, { before: any; after: any }>;
+  ip: string;
+  created: string;
+}
+
+export interface IAuditLogPage {
+  items: IAuditLogEntry[];
+  page: number;
+  perPage: number;
+  totalItems: number;
+  totalPages: number;
 }
 
 export interface IGameFilterVariables {
@@ -82,6 +153,46 @@ export interface IAdvertisementConfig {
   preload_interstitial?: boolean;
   preload_rewarded?: boolean;
   enable_consent_flow?: boolean;
+  lineage_id: string;
+  version: number;
+  is_latest: boolean;
+  status: "draft" | "in_review" | "approved" | "published" | "archived";
+  author: string;
+  approved_by: string;
+  weight?: number;
+  targeting?: IAdvertisementTargeting | null;
+  priority?: number;
+  is_default?: boolean;
+  active_from?: string;
+  active_until?: string;
+  created: string;
+  updated: string;
+}
+
+export interface IAdvertisementTargeting {
+  platforms?: ("ios" | "android")[];
+  countries?: string[];
+  min_app_version?: string;
+  max_app_version?: string;
+  min_level?: number;
+  max_level?: number;
+}
+
+export interface IPlacement {
+  id: string;
+  name: string;
+  description?: string;
+  ad_formats?: number[] | null;
+  game_id?: string;
+  created: string;
+  updated: string;
+}
+
+export interface IExperiment {
+  id: string;
+  key: string;
+  game_id: string;
+  running: boolean;
   created: string;
   updated: string;
 }
@@ -51,6 +51,8 @@ export const AdvertisementConfigEdit = () => {
               system: system || false,
               hidden: hidden || false,
               ...rest, // Include any additional field-specific properties
+              // Label the related games with their identifiers
+              ...(name === 'game_id' ? { displayField: 'game_id' } : {}),
             };
           }) || [];
 
@@ -78,10 +80,13 @@ export const AdvertisementConfigEdit = () => {
           {
             id: 'game_id_field',
             name: 'game_id',
-            type: 'json',
+            type: 'relation',
             required: true,
             system: false,
             hidden: false,
+            collectionId: 'games',
+            displayField: 'game_id',
+            maxSelect: 999,
           },
           {
             id: 'banner_ad_unit_id_field',
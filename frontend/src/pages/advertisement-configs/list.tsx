@@ -1,4 +1,4 @@
-import { useTranslate, useNavigation, type HttpError } from '@refinedev/core';
+import { useTranslate, useNavigation, useMany, type HttpError } from '@refinedev/core';
 import { CreateButton } from '@refinedev/antd';
 
 import { List, useTable, DateField } from '@refinedev/antd';
@@ -19,6 +19,14 @@ export const AdvertisementConfigList = () => {
   >({
     resource: 'advertisement_configs',
     filters: {
+      // every save creates a new version, only the latest one can be edited
+      permanent: [
+        {
+          field: 'is_latest',
+          operator: 'eq',
+          value: true,
+        },
+      ],
       initial: [
         {
           field: 'name',
@@ -32,6 +40,16 @@ export const AdvertisementConfigList = () => {
   const t = useTranslate();
   const { show } = useNavigation();
 
+  const gameIds = [...new Set(tableProps.dataSource?.flatMap(config => config.game_id ?? []) ?? [])];
+  const { query: gamesQuery } = useMany({
+    resource: 'games',
+    ids: gameIds,
+    queryOptions: {
+      enabled: gameIds.length > 0,
+    },
+  });
+  const gameNames = new Map(gamesQuery.data?.data?.map(game => [game.id, game.game_id]) ?? []);
+
   const handleNameFilterChange = (value: string) => {
     setNameFilter(value);
     setFilters([
@@ -82,7 +100,7 @@ export const AdvertisementConfigList = () => {
           key="game_id"
           dataIndex="game_id"
           title={t('advertisement_configs.fields.game_id')}
-          render={value => (Array.isArray(value) ? value.join(', ') : value)}
+          render={(value: string[]) => value?.map(id => gameNames.get(id) ?? id).join(', ')}
         />
         <Table.Column
           key="created"
@@ -1,4 +1,4 @@
-import { useShow, useOne } from '@refinedev/core';
+import { useShow, useMany } from '@refinedev/core';
 import { Show, TextField, DateField, BooleanField, NumberField } from '@refinedev/antd';
 import { Typography } from 'antd';
 
@@ -9,6 +9,14 @@ export const AdvertisementConfigShow = () => {
   const { data, isLoading } = query;
   const record = data?.data;
 
+  const { query: gamesQuery } = useMany({
+    resource: 'games',
+    ids: record?.game_id ?? [],
+    queryOptions: {
+      enabled: !!record?.game_id?.length,
+    },
+  });
+
   return (
     <Show isLoading={isLoading}>
       <Title level={5}>Name</Title>
@@ -19,7 +27,9 @@ export const AdvertisementConfigShow = () => {
 
       <Title level={5}>Game ID</Title>
       <TextField
-        value={Array.isArray(record?.game_id) ? record.game_id.join(', ') : record?.game_id}
+        value={record?.game_id
+          ?.map((id: string) => gamesQuery.data?.data?.find(game => game.id === id)?.game_id ?? id)
+          .join(', ')}
       />
 
       <Title level={5}>Banner Ad Unit ID</Title>
@@ -1,5 +1,6 @@
 import { Create, useForm, useSelect } from '@refinedev/antd';
 import { Form, Input, Switch, InputNumber, Select } from 'antd';
+import { AD_ACTIONS, AD_FORMATS, toEnumOptions } from '../../utils/adTypes';
 
 export const AdvertisementPlacementCreate = () => {
   const { formProps, saveButtonProps } = useForm();
@@ -8,19 +9,20 @@ export const AdvertisementPlacementCreate = () => {
     resource: 'advertisement_configs',
     optionLabel: 'name',
     optionValue: 'id',
+    filters: [
+      {
+        field: 'is_latest',
+        operator: 'eq',
+        value: true,
+      },
+    ],
   });
 
-  const placementOptions = [
-    'AppReady',
-    'LevelStart',
-    'Button/Undo/Click',
-    'Button/Hint/Click',
-    'Button/Shuffle/Click',
-    'Button/Revive/Click',
-    'LevelProgress_50',
-    'Screen/NoMoreMove/Open',
-    'Screen/LevelComplete/Open',
-  ];
+  const { selectProps: placementSelectProps } = useSelect({
+    resource: 'placements',
+    optionLabel: 'name',
+    optionValue: 'id',
+  });
 
   return (
     <Create saveButtonProps={saveButtonProps}>
@@ -38,13 +40,7 @@ export const AdvertisementPlacementCreate = () => {
           name="placement_id"
           rules={[{ required: true, message: 'Placement ID is required' }]}
         >
-          <Select placeholder="Select a placement">
-            {placementOptions.map(option => (
-              <Select.Option key={option} value={option}>
-                {option}
-              </Select.Option>
-            ))}
-          </Select>
+          <Select {...placementSelectProps} placeholder="Select a placement" />
         </Form.Item>
 
         <Form.Item
@@ -56,11 +52,11 @@ export const AdvertisementPlacementCreate = () => {
         </Form.Item>
 
         <Form.Item label="Ad Format" name="ad_format">
-          <InputNumber min={0} />
+          <Select options={toEnumOptions(AD_FORMATS)} />
         </Form.Item>
 
         <Form.Item label="Action" name="action">
-          <InputNumber min={0} />
+          <Select options={toEnumOptions(AD_ACTIONS)} />
         </Form.Item>
 
         <Form.Item label="Min Level" name="min_level">
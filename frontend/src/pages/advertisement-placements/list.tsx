@@ -1,5 +1,5 @@
 import { useTranslate, useNavigation, type HttpError } from '@refinedev/core';
-import { CreateButton } from '@refinedev/antd';
+import { CreateButton, useSelect } from '@refinedev/antd';
 
 import { List, useTable, DateField } from '@refinedev/antd';
 import { SearchOutlined } from '@ant-design/icons';
@@ -22,6 +22,14 @@ export const AdvertisementPlacementList = () => {
   >({
     resource: 'advertisements_placements',
     filters: {
+      // the previous config versions keep a copy of their placements
+      permanent: [
+        {
+          field: 'advertisement_id.is_latest',
+          operator: 'eq',
+          value: true,
+        },
+      ],
       initial: [
         {
           field: 'placement_id',
@@ -46,17 +54,11 @@ export const AdvertisementPlacementList = () => {
     ]);
   };
 
-  const placementOptions = [
-    'AppReady',
-    'LevelStart',
-    'Button/Undo/Click',
-    'Button/Hint/Click',
-    'Button/Shuffle/Click',
-    'Button/Revive/Click',
-    'LevelProgress_50',
-    'Screen/NoMoreMove/Open',
-    'Screen/LevelComplete/Open',
-  ];
+  const { selectProps: placementSelectProps } = useSelect({
+    resource: 'placements',
+    optionLabel: 'name',
+    optionValue: 'id',
+  });
 
   return (
     <List
@@ -68,13 +70,8 @@ export const AdvertisementPlacementList = () => {
           onChange={handlePlacementIdFilterChange}
           style={{ width: 250, marginRight: 8 }}
           allowClear
-        >
-          {placementOptions.map(option => (
-            <Select.Option key={option} value={option}>
-              {option}
-            </Select.Option>
-          ))}
-        </Select>,
+          options={placementSelectProps.options}
+        />,
         <CreateButton key="create" />,
       ]}
     >
@@ -101,6 +98,9 @@ export const AdvertisementPlacementList = () => {
           key="placement_id"
           dataIndex="placement_id"
           title={t('advertisement_placements.fields.placement_id')}
+          render={(value: string) =>
+            placementSelectProps.options?.find(option => option.value === value)?.label ?? value
+          }
         />
         <Table.Column
           key="ad_format"
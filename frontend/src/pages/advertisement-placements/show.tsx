@@ -9,13 +9,21 @@ export const AdvertisementPlacementShow = () => {
   const { data, isLoading } = query;
   const record = data?.data;
 
+  const { query: placementQuery } = useOne({
+    resource: 'placements',
+    id: record?.placement_id,
+    queryOptions: {
+      enabled: !!record?.placement_id,
+    },
+  });
+
   return (
     <Show isLoading={isLoading}>
       <Title level={5}>Advertisement ID</Title>
       <TextField value={record?.advertisement_id} />
 
       <Title level={5}>Placement ID</Title>
-      <TextField value={record?.placement_id} />
+      <TextField value={placementQuery.data?.data?.name ?? record?.placement_id} />
 
       <Title level={5}>Ad Format</Title>
       <NumberField value={record?.ad_format} />
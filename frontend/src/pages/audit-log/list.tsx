@@ -0,0 +1,140 @@
+import { useCustom, useTranslate } from '@refinedev/core';
+import { List, DateField } from '@refinedev/antd';
+import { SearchOutlined } from '@ant-design/icons';
+import { Table, Input, Select, Tag, Typography } from 'antd';
+import { useState } from 'react';
+
+import type { AuditAction, IAuditLogEntry, IAuditLogPage } from '../../interfaces';
+
+const AUDIT_ACTION_COLORS: Record<AuditAction, string> = {
+  create: 'green',
+  update: 'blue',
+  delete: 'red',
+};
+
+const AUDITED_COLLECTIONS = ['games', 'advertisement_configs', 'advertisements_placements'];
+
+const formatValue = (value: any) =>
+  value === null || value === undefined ? '—' : JSON.stringify(value);
+
+export const AuditLogList = () => {
+  const t = useTranslate();
+  const [field, setField] = useState('');
+  const [collection, setCollection] = useState<string>();
+  const [action, setAction] = useState<AuditAction>();
+  const [page, setPage] = useState(1);
+  const [perPage, setPerPage] = useState(30);
+
+  // Entries filtered by the server, most recent first
+  const { query } = useCustom<IAuditLogPage>({
+    url: '/api/audit-log',
+    method: 'get',
+    config: {
+      query: { field, collection, action, page, perPage },
+    },
+  });
+
+  const { data, isLoading } = query;
+
+  return (
+    <List
+      headerButtons={[
+        <Input
+          key="field-filter"
+          placeholder={t('audit_log.filter.field.placeholder')}
+          prefix={<SearchOutlined />}
+          value={field}
+          onChange={e => {
+            setField(e.target.value.trim());
+            setPage(1);
+          }}
+          style={{ width: 250 }}
+          allowClear
+        />,
+        <Select
+          key="collection-filter"
+          placeholder={t('audit_log.filter.collection.placeholder')}
+          value={collection}
+          onChange={value => {
+            setCollection(value);
+            setPage(1);
+          }}
+          options={AUDITED_COLLECTIONS.map(value => ({ value, label: value }))}
+          style={{ width: 220 }}
+          allowClear
+        />,
+        <Select
+          key="action-filter"
+          placeholder={t('audit_log.filter.action.placeholder')}
+          value={action}
+          onChange={value => {
+            setAction(value);
+            setPage(1);
+          }}
+          options={Object.keys(AUDIT_ACTION_COLORS).map(value => ({ value, label: value }))}
+          style={{ width: 150 }}
+          allowClear
+        />,
+      ]}
+    >
+      <Table<IAuditLogEntry>
+        dataSource={data?.data?.items}
+        loading={isLoading}
+        rowKey="id"
+        pagination={{
+          current: page,
+          pageSize: perPage,
+          total: data?.data?.totalItems,
+          onChange: (current, size) => {
+            setPage(current);
+            setPerPage(size);
+          },
+        }}
+      >
+        <Table.Column
+          key="created"
+          dataIndex="created"
+          title={t('audit_log.fields.created')}
+          render={value => <DateField value={value} format="LLL" />}
+        />
+        <Table.Column
+          key="actor_email"
+          dataIndex="actor_email"
+          title={t('audit_log.fields.actor')}
+        />
+        <Table.Column
+          key="action"
+          dataIndex="action"
+          title={t('audit_log.fields.action')}
+          render={(value: AuditAction) => <Tag color={AUDIT_ACTION_COLORS[value]}>{value}</Tag>}
+        />
+        <Table.Column
+          key="collection"
+          dataIndex="collection"
+          title={t('audit_log.fields.collection')}
+        />
+        <Table.Column
+          key="record_id"
+          dataIndex="record_id"
+          title={t('audit_log.fields.record_id')}
+        />
+        <Table.Column<IAuditLogEntry>
+          key="changes"
+          dataIndex="changes"
+          title={t('audit_log.fields.changes')}
+          render={(_, record) =>
+            Object.entries(record.changes || {}).map(([name, change]) => (
+              <div key={name}>
+                <Typography.Text strong>{name}</Typography.Text>:{' '}
+                <Typography.Text type="secondary">{formatValue(change.before)}</Typography.Text>
+                {' → '}
+                {formatValue(change.after)}
+              </div>
+            ))
+          }
+        />
+        <Table.Column key="ip" dataIndex="ip" title={t('audit_log.fields.ip')} />
+      </Table>
+    </List>
+  );
+};
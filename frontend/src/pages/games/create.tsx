@@ -7,6 +7,7 @@ import { getStatusCode } from '../../utils';
 import { DynamicForm } from '../../components/form/dynamic-form';
 import type { IGame } from '../../interfaces';
 import type { DynamicField } from '../../components/form/dynamic-form';
+import { GAME_STATUSES } from '../../utils/gameStatus';
 
 export const GameCreate = () => {
   const t = useTranslate();
@@ -59,7 +60,7 @@ export const GameCreate = () => {
         setFields(dynamicFields);
       } catch (error) {
         console.error('Failed to fetch collection schema:', error);
-        // Fallback to hardcoded field for now
+        // Fallback to hardcoded fields for now
         setFields([
           {
             id: 'game_id_field',
@@ -69,6 +70,74 @@ export const GameCreate = () => {
             system: false,
             hidden: false,
           },
+          {
+            id: 'ios_bundle_id_field',
+            name: 'ios_bundle_id',
+            type: 'text',
+            required: false,
+            system: false,
+            hidden: false,
+          },
+          {
+            id: 'android_package_name_field',
+            name: 'android_package_name',
+            type: 'text',
+            required: false,
+            system: false,
+            hidden: false,
+          },
+          {
+            id: 'name_field',
+            name: 'name',
+            type: 'text',
+            required: false,
+            system: false,
+            hidden: false,
+          },
+          {
+            id: 'platforms_field',
+            name: 'platforms',
+            type: 'select',
+            required: false,
+            system: false,
+            hidden: false,
+            values: ['ios', 'android'],
+            maxSelect: 2,
+          },
+          {
+            id: 'app_store_url_field',
+            name: 'app_store_url',
+            type: 'url',
+            required: false,
+            system: false,
+            hidden: false,
+          },
+          {
+            id: 'play_store_url_field',
+            name: 'play_store_url',
+            type: 'url',
+            required: false,
+            system: false,
+            hidden: false,
+          },
+          {
+            id: 'team_field',
+            name: 'team',
+            type: 'text',
+            required: false,
+            system: false,
+            hidden: false,
+          },
+          {
+            id: 'status_field',
+            name: 'status',
+            type: 'select',
+            required: false,
+            system: false,
+            hidden: false,
+            values: GAME_STATUSES,
+            maxSelect: 1,
+          },
         ]);
       } finally {
         setLoading(false);
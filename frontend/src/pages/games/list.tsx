@@ -9,11 +9,12 @@ import { CreateButton } from '@refinedev/antd';
 
 import { List, useTable, DateField, FilterDropdown } from '@refinedev/antd';
 import { SearchOutlined } from '@ant-design/icons';
-import { Table, Input, Typography, theme, Button } from 'antd';
+import { Table, Input, Typography, theme, Button, Tag } from 'antd';
 import { useState } from 'react';
 
 import type { IGame, IGameFilterVariables } from '../../interfaces';
 import { GameActions } from '../../components/gameActions';
+import { GAME_STATUS_COLORS } from '../../utils/gameStatus';
 
 export const GameList = () => {
   const { token } = theme.useToken();
@@ -76,6 +77,16 @@ export const GameList = () => {
         }}
       >
         <Table.Column key="game_id" dataIndex="game_id" title={t('games.fields.game_id')} />
+        <Table.Column key="name" dataIndex="name" title={t('games.fields.name')} />
+        <Table.Column key="team" dataIndex="team" title={t('games.fields.team')} />
+        <Table.Column
+          key="status"
+          dataIndex="status"
+          title={t('games.fields.status')}
+          render={(value: IGame['status']) =>
+            value ? <Tag color={GAME_STATUS_COLORS[value]}>{value}</Tag> : null
+          }
+        />
         <Table.Column
           key="created"
           dataIndex="created"
@@ -1,8 +1,10 @@
 import { useShow, useTranslate } from '@refinedev/core';
 import type { IGame } from '../../interfaces';
 import { List, ListButton } from '@refinedev/antd';
-import { Divider, Flex, Row, Col, Typography, Skeleton } from 'antd';
-import { LeftOutlined } from '@ant-design/icons';
+import { Avatar, Divider, Flex, Row, Col, Typography, Skeleton, Space, Tag } from 'antd';
+import { AppstoreOutlined, LeftOutlined } from '@ant-design/icons';
+import { pb } from '../../authProvider';
+import { GAME_STATUS_COLORS } from '../../utils/gameStatus';
 
 export const GameShow = () => {
   const t = useTranslate();
@@ -28,7 +30,14 @@ export const GameShow = () => {
               }}
             />
           ) : (
-            record?.game_id
+            <Space>
+              <Avatar
+                shape="square"
+                src={record?.icon ? pb.files.getURL(record, record.icon, { thumb: '64x64' }) : undefined}
+                icon={<AppstoreOutlined />}
+              />
+              {record?.name || record?.game_id}
+            </Space>
           )
         }
       >
@@ -41,6 +50,86 @@ export const GameShow = () => {
               </Typography.Text>
             </div>
           </Col>
+          <Col span={12}>
+            <div>
+              <Typography.Title level={5}>{t('games.fields.ios_bundle_id')}</Typography.Title>
+              <Typography.Text>
+                {isLoading ? <Skeleton.Input active /> : record?.ios_bundle_id || '-'}
+              </Typography.Text>
+            </div>
+          </Col>
+          <Col span={12}>
+            <div>
+              <Typography.Title level={5}>{t('games.fields.android_package_name')}</Typography.Title>
+              <Typography.Text>
+                {isLoading ? <Skeleton.Input active /> : record?.android_package_name || '-'}
+              </Typography.Text>
+            </div>
+          </Col>
+          <Col span={12}>
+            <div>
+              <Typography.Title level={5}>{t('games.fields.name')}</Typography.Title>
+              <Typography.Text>
+                {isLoading ? <Skeleton.Input active /> : record?.name || '-'}
+              </Typography.Text>
+            </div>
+          </Col>
+          <Col span={12}>
+            <div>
+              <Typography.Title level={5}>{t('games.fields.status')}</Typography.Title>
+              <Typography.Text>
+                {isLoading ? <Skeleton.Input active /> : record?.status ? (
+                  <Tag color={GAME_STATUS_COLORS[record.status]}>{record.status}</Tag>
+                ) : (
+                  '-'
+                )}
+              </Typography.Text>
+            </div>
+          </Col>
+          <Col span={12}>
+            <div>
+              <Typography.Title level={5}>{t('games.fields.platforms')}</Typography.Title>
+              <Typography.Text>
+                {isLoading ? <Skeleton.Input active /> : record?.platforms?.join(', ') || '-'}
+              </Typography.Text>
+            </div>
+          </Col>
+          <Col span={12}>
+            <div>
+              <Typography.Title level={5}>{t('games.fields.team')}</Typography.Title>
+              <Typography.Text>
+                {isLoading ? <Skeleton.Input active /> : record?.team || '-'}
+              </Typography.Text>
+            </div>
+          </Col>
+          <Col span={12}>
+            <div>
+              <Typography.Title level={5}>{t('games.fields.app_store_url')}</Typography.Title>
+              <Typography.Text>
+                {isLoading ? <Skeleton.Input active /> : record?.app_store_url ? (
+                  <Typography.Link href={record.app_store_url} target="_blank">
+                    {record.app_store_url}
+                  </Typography.Link>
+                ) : (
+                  '-'
+                )}
+              </Typography.Text>
+            </div>
+          </Col>
+          <Col span={12}>
+            <div>
+              <Typography.Title level={5}>{t('games.fields.play_store_url')}</Typography.Title>
+              <Typography.Text>
+                {isLoading ? <Skeleton.Input active /> : record?.play_store_url ? (
+                  <Typography.Link href={record.play_store_url} target="_blank">
+                    {record.play_store_url}
+                  </Typography.Link>
+                ) : (
+                  '-'
+                )}
+              </Typography.Text>
+            </div>
+          </Col>
         </Row>
       </List>
     </>
@@ -0,0 +1,25 @@
+// Named values of the numeric advertisement enums, mirroring the backend
+// adtypes package. The codes are stable, new values are only appended.
+
+export const AD_FORMATS = [
+  'banner',
+  'interstitial',
+  'rewarded',
+  'rewarded_interstitial',
+  'app_open',
+] as const;
+
+export const AD_ACTIONS = ['show', 'load', 'hide', 'destroy'] as const;
+
+export const BANNER_POSITIONS = [
+  'top',
+  'bottom',
+  'top_left',
+  'top_right',
+  'bottom_left',
+  'bottom_right',
+  'center',
+] as const;
+
+export const toEnumOptions = (names: readonly string[]) =>
+  names.map((name, code) => ({ label: `${code} (${name})`, value: code }));
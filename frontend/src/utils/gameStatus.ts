@@ -0,0 +1,10 @@
+import type { GameStatus } from '../interfaces';
+
+// Lifecycle statuses of the games, mirroring the backend games status field.
+export const GAME_STATUSES: GameStatus[] = ['development', 'live', 'sunset'];
+
+export const GAME_STATUS_COLORS: Record<GameStatus, string> = {
+  development: 'blue',
+  live: 'green',
+  sunset: 'default',
+};